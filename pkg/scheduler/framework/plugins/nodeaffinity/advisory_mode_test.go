@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestFilterRejectsFailingRequiredTermByDefault(t *testing.T) {
+	pod := nodeSelectorPod("zone", "a")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Fatal("expected a failing required term to reject the node with AdvisoryMode off")
+	}
+}
+
+func TestFilterAdmitsFailingRequiredTermUnderAdvisoryMode(t *testing.T) {
+	pod := nodeSelectorPod("zone", "a")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), AdvisoryMode: true}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Fatalf("expected AdvisoryMode to admit a node failing a required term, got status: %v", status)
+	}
+}
+
+func TestScorePenalizesFailingRequiredTermUnderAdvisoryMode(t *testing.T) {
+	pod := nodeSelectorPod("zone", "a")
+	failing := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "failing"}}
+	matching := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "matching", Labels: map[string]string{"zone": "a"}}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{failing, matching})
+
+	pl, _ := New(nil, fh)
+	na := pl.(*NodeAffinity)
+	na.AdvisoryMode = true
+
+	failingScore, status := na.Score(context.Background(), nil, pod, "failing")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error scoring failing: %v", status)
+	}
+	if failingScore != framework.MinNodeScore {
+		t.Errorf("expected a node failing the required term to score %d, got %d", framework.MinNodeScore, failingScore)
+	}
+
+	matchingScore, status := na.Score(context.Background(), nil, pod, "matching")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error scoring matching: %v", status)
+	}
+	if matchingScore != 0 {
+		t.Errorf("expected a node satisfying the required term to score 0 (no penalty applied), got %d", matchingScore)
+	}
+}
+
+func TestScoreIgnoresRequiredTermFailureWhenAdvisoryModeDisabled(t *testing.T) {
+	pod := nodeSelectorPod("zone", "a")
+	failing := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "failing"}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{failing})
+
+	pl, _ := New(nil, fh)
+	score, status := pl.(*NodeAffinity).Score(context.Background(), nil, pod, "failing")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error: %v", status)
+	}
+	if score != 0 {
+		t.Errorf("expected no penalty with AdvisoryMode off, got %d", score)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// disallowedSelectorKey returns the first node label key referenced by pod's NodeSelector or NodeAffinity terms
+// that isn't in allowed, and true, or "", false if every referenced key is allowed. An empty allowed list means no
+// restriction and always returns false.
+func disallowedSelectorKey(pod *v1.Pod, allowed []string) (string, bool) {
+	if len(allowed) == 0 {
+		return "", false
+	}
+	isAllowed := func(key string) bool {
+		for _, k := range allowed {
+			if k == key {
+				return true
+			}
+		}
+		return false
+	}
+	for k := range pod.Spec.NodeSelector {
+		if !isAllowed(k) {
+			return k, true
+		}
+	}
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return "", false
+	}
+	nodeAffinity := affinity.NodeAffinity
+	if required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+		for _, term := range required.NodeSelectorTerms {
+			for _, expr := range term.MatchExpressions {
+				if !isAllowed(expr.Key) {
+					return expr.Key, true
+				}
+			}
+			for _, field := range term.MatchFields {
+				if !isAllowed(field.Key) {
+					return field.Key, true
+				}
+			}
+		}
+	}
+	for _, preferred := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		for _, expr := range preferred.Preference.MatchExpressions {
+			if !isAllowed(expr.Key) {
+				return expr.Key, true
+			}
+		}
+		for _, field := range preferred.Preference.MatchFields {
+			if !isAllowed(field.Key) {
+				return field.Key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// allowedSelectorKeyReason formats the Filter failure message for a disallowed node label key.
+func allowedSelectorKeyReason(key string) string {
+	return fmt.Sprintf("pod's node affinity references node label key %q, which is outside the cluster's allowed selector keys", key)
+}
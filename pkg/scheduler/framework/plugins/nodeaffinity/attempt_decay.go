@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"math"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+// SchedulingAttemptAnnotation records how many times scheduling has already been attempted for a pod without it
+// landing anywhere, keyed off by scoreWeightedSumWithDecay when ScoringMode is
+// ScoringModeWeightedSumWithAttemptDecay. The scheduler itself is expected to set/update this as it retries a pod;
+// this plugin only ever reads it. Missing or unparsable, it's treated as 0 attempts, i.e. no decay yet.
+const SchedulingAttemptAnnotation = "scheduling.k8s.io/scheduling-attempt"
+
+// schedulingAttempts returns pod's recorded attempt count per SchedulingAttemptAnnotation, or 0 if it's absent,
+// negative, or not a valid integer.
+func schedulingAttempts(pod *v1.Pod) int {
+	raw, ok := pod.Annotations[SchedulingAttemptAnnotation]
+	if !ok {
+		return 0
+	}
+	attempts, err := strconv.Atoi(raw)
+	if err != nil || attempts < 0 {
+		return 0
+	}
+	return attempts
+}
+
+// scoreWeightedSumWithDecay scores node by the standard weighted sum of terms' Weight for every term node matches,
+// same as the rest of the scheduling stack's own preferred-term scoring, except each term's effective weight is
+// first multiplied by decayMultiplier(decayFactor, attempts), so a pod that has failed to schedule several times
+// already presses less hard for its preferred nodes. The sum is clamped to [framework.MinNodeScore,
+// framework.MaxNodeScore]. A pod with no preferred terms, or none it matches, scores 0.
+func scoreWeightedSumWithDecay(node *v1.Node, terms []v1.PreferredSchedulingTerm, attempts int, decayFactor float64) int64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	multiplier := decayMultiplier(decayFactor, attempts)
+
+	var sum float64
+	for _, term := range terms {
+		selector, err := v1helper.NodeSelectorRequirementsAsSelector(term.Preference.MatchExpressions)
+		if err != nil || !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		sum += float64(term.Weight) * multiplier
+	}
+
+	if sum > float64(framework.MaxNodeScore) {
+		sum = float64(framework.MaxNodeScore)
+	}
+	if sum < float64(framework.MinNodeScore) {
+		sum = float64(framework.MinNodeScore)
+	}
+	return int64(sum)
+}
+
+// decayMultiplier returns (1-decayFactor)^attempts, geometrically shrinking toward (but never below) zero as
+// attempts grows. decayFactor is clamped to [0,1]; a decayFactor of 0, or 0 attempts, returns 1 (no decay).
+func decayMultiplier(decayFactor float64, attempts int) float64 {
+	if decayFactor <= 0 || attempts <= 0 {
+		return 1
+	}
+	if decayFactor > 1 {
+		decayFactor = 1
+	}
+	return math.Pow(1-decayFactor, float64(attempts))
+}
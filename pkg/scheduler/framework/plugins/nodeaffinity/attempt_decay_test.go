@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func podWithAttempts(attempts int) *v1.Pod {
+	pod := preferredTermsPod(preferredTerm(100, "zone", "us-east-1"))
+	pod.Annotations = map[string]string{SchedulingAttemptAnnotation: strconv.Itoa(attempts)}
+	return pod
+}
+
+func TestScoreDecaysEffectiveWeightAcrossAttempts(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"zone": "us-east-1"}}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{node})
+
+	pl, _ := New(nil, fh)
+	na := pl.(*NodeAffinity)
+	na.ScoringMode = ScoringModeWeightedSumWithAttemptDecay
+	na.AttemptWeightDecayFactor = 0.5
+
+	var scores []int64
+	for _, attempts := range []int{0, 1, 2, 3} {
+		pod := podWithAttempts(attempts)
+		score, status := na.Score(context.Background(), nil, pod, "node")
+		if !status.IsSuccess() {
+			t.Fatalf("unexpected error scoring attempt %d: %v", attempts, status)
+		}
+		scores = append(scores, score)
+	}
+
+	for i := 1; i < len(scores); i++ {
+		if scores[i] >= scores[i-1] {
+			t.Errorf("expected strictly decreasing scores across attempts, got %v", scores)
+			break
+		}
+	}
+}
+
+func TestScoreNoDecayWithZeroFactor(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"zone": "us-east-1"}}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{node})
+
+	pl, _ := New(nil, fh)
+	na := pl.(*NodeAffinity)
+	na.ScoringMode = ScoringModeWeightedSumWithAttemptDecay
+
+	score0, _ := na.Score(context.Background(), nil, podWithAttempts(0), "node")
+	score5, _ := na.Score(context.Background(), nil, podWithAttempts(5), "node")
+
+	if score0 != score5 {
+		t.Errorf("expected no decay with a zero AttemptWeightDecayFactor, got %d and %d", score0, score5)
+	}
+}
+
+func TestSchedulingAttemptsMissingOrInvalidDefaultsToZero(t *testing.T) {
+	if got := schedulingAttempts(&v1.Pod{}); got != 0 {
+		t.Errorf("expected 0 attempts for a pod with no annotation, got %d", got)
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{SchedulingAttemptAnnotation: "not-a-number"}}}
+	if got := schedulingAttempts(pod); got != 0 {
+		t.Errorf("expected 0 attempts for an unparsable annotation, got %d", got)
+	}
+}
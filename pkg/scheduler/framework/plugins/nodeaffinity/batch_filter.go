@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/pkg/scheduler/algorithm/predicates"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+// FilterBatch runs the required node selector/affinity predicate for every pod in pods against the single node,
+// extracting labels.Set(node.Labels) once and reusing it across all of them, for a caller (e.g. a scheduler
+// extender call) that filters many pods against the same node at once and would otherwise pay for identical
+// labels.Set extraction per pod. Returned statuses are aligned with pods by index.
+func FilterBatch(pods []*v1.Pod, node *v1.Node) []*framework.Status {
+	set := labels.Set(node.Labels)
+	statuses := make([]*framework.Status, len(pods))
+	for i, pod := range pods {
+		matched, err := MatchesLabels(pod, set, node.Name)
+		switch {
+		case err != nil:
+			statuses[i] = framework.NewStatus(framework.Error, err.Error())
+		case !matched:
+			statuses[i] = framework.NewStatus(framework.UnschedulableAndUnresolvable, predicates.ErrNodeSelectorNotMatch.GetReason())
+		}
+	}
+	return statuses
+}
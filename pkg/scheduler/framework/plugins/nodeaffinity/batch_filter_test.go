@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestFilterBatchAlignsStatusesByIndex(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"zone": "a"}}}
+	pods := []*v1.Pod{
+		nodeSelectorPod("zone", "a"),
+		nodeSelectorPod("zone", "b"),
+		{},
+	}
+
+	statuses := FilterBatch(pods, node)
+	if len(statuses) != len(pods) {
+		t.Fatalf("expected %d statuses, got %d", len(pods), len(statuses))
+	}
+	if !statuses[0].IsSuccess() {
+		t.Errorf("expected pods[0] to match, got status: %v", statuses[0])
+	}
+	if statuses[1].IsSuccess() {
+		t.Errorf("expected pods[1] to fail to match")
+	}
+	if !statuses[2].IsSuccess() {
+		t.Errorf("expected pods[2] (no affinity) to match, got status: %v", statuses[2])
+	}
+}
+
+func benchmarkPods(n int) []*v1.Pod {
+	pods := make([]*v1.Pod, n)
+	for i := range pods {
+		pods[i] = nodeSelectorPod("zone", "a")
+	}
+	return pods
+}
+
+func BenchmarkFilterBatch(b *testing.B) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"zone": "a"}}}
+	pods := benchmarkPods(100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FilterBatch(pods, node)
+	}
+}
+
+func BenchmarkFilterPerPod(b *testing.B) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"zone": "a"}}}
+	pods := benchmarkPods(100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, pod := range pods {
+			MatchesLabels(pod, labels.Set(node.Labels), node.Name)
+		}
+	}
+}
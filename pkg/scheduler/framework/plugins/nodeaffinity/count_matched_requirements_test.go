@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestScoreCountMatchedRequirementsFavorsMoreMatches(t *testing.T) {
+	pod := preferredTermsPod(preferredTerm(1, "zone", "a"))
+	pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].Preference.MatchExpressions = append(
+		pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].Preference.MatchExpressions,
+		v1.NodeSelectorRequirement{Key: "rack", Operator: v1.NodeSelectorOpIn, Values: []string{"y"}},
+		v1.NodeSelectorRequirement{Key: "gpu", Operator: v1.NodeSelectorOpIn, Values: []string{"true"}},
+	)
+
+	threeMatches := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "three", Labels: map[string]string{"zone": "a", "rack": "y", "gpu": "true"}}}
+	oneMatch := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "one", Labels: map[string]string{"zone": "a"}}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{threeMatches, oneMatch})
+
+	pl, _ := New(nil, fh)
+	na := pl.(*NodeAffinity)
+	na.ScoringMode = ScoringModeCountMatchedRequirements
+
+	threeScore, status := na.Score(context.Background(), nil, pod, "three")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error scoring three: %v", status)
+	}
+	oneScore, status := na.Score(context.Background(), nil, pod, "one")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error scoring one: %v", status)
+	}
+
+	if threeScore != framework.MaxNodeScore {
+		t.Errorf("expected a node matching all 3 requirements to score %d, got %d", framework.MaxNodeScore, threeScore)
+	}
+	if oneScore <= 0 || oneScore >= threeScore {
+		t.Errorf("expected the node matching 1 of 3 requirements to score between 0 and %d, got %d", threeScore, oneScore)
+	}
+}
+
+func TestScoreCountMatchedRequirementsNoRequirements(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	if got := scoreCountMatchedRequirements(node, nil); got != 0 {
+		t.Errorf("expected a pod with no preferred terms to score 0, got %d", got)
+	}
+}
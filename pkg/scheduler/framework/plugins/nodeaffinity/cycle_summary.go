@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+// cycleSummaryStateKey is the CycleState key nodeaffinity accumulates per-cycle Filter outcomes under, when
+// LogCycleSummary is enabled.
+const cycleSummaryStateKey framework.StateKey = "NodeAffinity/CycleSummary"
+
+// cycleSummary counts how many nodes have passed or failed Filter so far this scheduling cycle.
+type cycleSummary struct {
+	passed int
+	failed int
+}
+
+// Clone returns a copy of the summary, satisfying framework.StateData. A shallow copy is enough since cycleSummary
+// only holds value fields.
+func (s *cycleSummary) Clone() framework.StateData {
+	clone := *s
+	return &clone
+}
+
+// recordCycleOutcome increments the CycleState's accumulated pass/fail counts for this scheduling cycle. It's a
+// no-op if state is nil, which happens in tests that call Filter directly without a real scheduling cycle.
+func recordCycleOutcome(state *framework.CycleState, passed bool) {
+	if state == nil {
+		return
+	}
+	state.Lock()
+	defer state.Unlock()
+
+	summary := &cycleSummary{}
+	if existing, err := state.Read(cycleSummaryStateKey); err == nil {
+		if s, ok := existing.(*cycleSummary); ok {
+			summary = s
+		}
+	}
+	if passed {
+		summary.passed++
+	} else {
+		summary.failed++
+	}
+	state.Write(cycleSummaryStateKey, summary)
+}
+
+// readCycleSummary returns the accumulated pass/fail counts for this scheduling cycle, or a zero summary if Filter
+// never ran (or never recorded anything, e.g. LogCycleSummary was disabled for the whole cycle).
+func readCycleSummary(state *framework.CycleState) cycleSummary {
+	if state == nil {
+		return cycleSummary{}
+	}
+	state.RLock()
+	defer state.RUnlock()
+
+	existing, err := state.Read(cycleSummaryStateKey)
+	if err != nil {
+		return cycleSummary{}
+	}
+	if s, ok := existing.(*cycleSummary); ok {
+		return *s
+	}
+	return cycleSummary{}
+}
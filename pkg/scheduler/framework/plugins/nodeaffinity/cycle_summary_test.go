@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestFilterAccumulatesCycleSummary(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1")
+	matching := schedulernodeinfo.NewNodeInfo()
+	matching.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"zone": "us-east-1"}}})
+	mismatched := schedulernodeinfo.NewNodeInfo()
+	mismatched.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"zone": "us-west-1"}}})
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), LogCycleSummary: true}
+	state := framework.NewCycleState()
+
+	pl.Filter(context.Background(), state, pod, matching)
+	pl.Filter(context.Background(), state, pod, mismatched)
+	pl.Filter(context.Background(), state, pod, mismatched)
+
+	summary := readCycleSummary(state)
+	if summary.passed != 1 || summary.failed != 2 {
+		t.Errorf("expected 1 passed and 2 failed, got %+v", summary)
+	}
+
+	status := pl.PostFilter(context.Background(), state, pod, nil, nil)
+	if !status.IsSuccess() {
+		t.Errorf("expected PostFilter to always succeed, got %v", status)
+	}
+}
+
+func TestFilterDoesNotAccumulateCycleSummaryByDefault(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1")
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"zone": "us-east-1"}}})
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	state := framework.NewCycleState()
+
+	pl.Filter(context.Background(), state, pod, nodeInfo)
+
+	summary := readCycleSummary(state)
+	if summary.passed != 0 || summary.failed != 0 {
+		t.Errorf("expected no accumulation with LogCycleSummary disabled, got %+v", summary)
+	}
+}
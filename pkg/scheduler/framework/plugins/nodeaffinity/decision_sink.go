@@ -0,0 +1,31 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DecisionSink records every affinity-based scheduling decision Filter makes, for compliance regimes that need an
+// auditable record beyond what metrics/logs provide. RecordDecision is called synchronously from Filter, once per
+// pod/node pair that reaches the required-term evaluation, after the match/mismatch outcome is known but before
+// AdvisoryMode or VerboseReasons have a chance to alter the returned Status; matched and reason always describe the
+// raw required-term evaluation. A node Filter rejects earlier (RequiredPresentKeys, TopologyConsistency,
+// AllowedSelectorKeys) is not recorded.
+type DecisionSink interface {
+	RecordDecision(podRef types.NamespacedName, nodeName string, matched bool, reason string)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type recordedDecision struct {
+	podRef   types.NamespacedName
+	nodeName string
+	matched  bool
+	reason   string
+}
+
+type capturingDecisionSink struct {
+	decisions []recordedDecision
+}
+
+func (s *capturingDecisionSink) RecordDecision(podRef types.NamespacedName, nodeName string, matched bool, reason string) {
+	s.decisions = append(s.decisions, recordedDecision{podRef: podRef, nodeName: nodeName, matched: matched, reason: reason})
+}
+
+func TestFilterRecordsDecisionsToSink(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1")
+	pod.Namespace = "default"
+	pod.Name = "test-pod"
+
+	sink := &capturingDecisionSink{}
+	pl := &NodeAffinity{
+		selectorCache: newSelectorCache(defaultSelectorCacheSize),
+		DecisionSink:  sink,
+	}
+
+	matching := nodeInfoWithLabels("match", map[string]string{"zone": "us-east-1"})
+	if status := pl.Filter(context.Background(), nil, pod, matching); !status.IsSuccess() {
+		t.Fatalf("expected the node to match, got: %v", status)
+	}
+
+	mismatched := nodeInfoWithLabels("mismatch", map[string]string{"zone": "us-west-1"})
+	if status := pl.Filter(context.Background(), nil, pod, mismatched); status.IsSuccess() {
+		t.Fatal("expected the node to be rejected")
+	}
+
+	if len(sink.decisions) != 2 {
+		t.Fatalf("expected 2 recorded decisions, got %d", len(sink.decisions))
+	}
+
+	wantPodRef := types.NamespacedName{Namespace: "default", Name: "test-pod"}
+	first := sink.decisions[0]
+	if first.podRef != wantPodRef || first.nodeName != "match" || !first.matched || first.reason != "" {
+		t.Errorf("unexpected first decision: %+v", first)
+	}
+
+	second := sink.decisions[1]
+	if second.podRef != wantPodRef || second.nodeName != "mismatch" || second.matched || second.reason == "" {
+		t.Errorf("unexpected second decision: %+v", second)
+	}
+}
+
+func TestFilterRecordsNoDecisionsWithoutSink(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1")
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	nodeInfo := nodeInfoWithLabels("node-1", map[string]string{"zone": "us-east-1"})
+
+	if status := pl.Filter(context.Background(), nil, pod, nodeInfo); !status.IsSuccess() {
+		t.Fatalf("expected the node to match, got: %v", status)
+	}
+}
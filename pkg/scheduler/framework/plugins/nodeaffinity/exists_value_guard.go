@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// invalidExistsValues returns the first NodeSelectorRequirement in pod's NodeAffinity terms whose Operator is Exists
+// or DoesNotExist but which erroneously carries a non-empty Values, and true, or a zero requirement and false if
+// none does. The API documents that Exists/DoesNotExist must not specify Values; some callers silently ignore a
+// violation instead of enforcing it, letting a misconfigured pod appear to schedule correctly while its stray
+// Values are never actually consulted.
+func invalidExistsValues(pod *v1.Pod) (v1.NodeSelectorRequirement, bool) {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return v1.NodeSelectorRequirement{}, false
+	}
+	nodeAffinity := affinity.NodeAffinity
+
+	violatesGuard := func(req v1.NodeSelectorRequirement) bool {
+		return (req.Operator == v1.NodeSelectorOpExists || req.Operator == v1.NodeSelectorOpDoesNotExist) && len(req.Values) > 0
+	}
+
+	if required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+		for _, term := range required.NodeSelectorTerms {
+			for _, expr := range term.MatchExpressions {
+				if violatesGuard(expr) {
+					return expr, true
+				}
+			}
+			for _, field := range term.MatchFields {
+				if violatesGuard(field) {
+					return field, true
+				}
+			}
+		}
+	}
+	for _, preferred := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		for _, expr := range preferred.Preference.MatchExpressions {
+			if violatesGuard(expr) {
+				return expr, true
+			}
+		}
+		for _, field := range preferred.Preference.MatchFields {
+			if violatesGuard(field) {
+				return field, true
+			}
+		}
+	}
+	return v1.NodeSelectorRequirement{}, false
+}
+
+// existsValueGuardReason formats the PreFilter failure message for a requirement violating the Exists/DoesNotExist
+// value guard.
+func existsValueGuardReason(req v1.NodeSelectorRequirement) string {
+	return fmt.Sprintf("node affinity requirement on key %q uses operator %q, which must not specify Values, but has %v", req.Key, req.Operator, req.Values)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+func doesNotExistPod(values []string) *v1.Pod {
+	return &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{MatchExpressions: []v1.NodeSelectorRequirement{
+								{Key: "zone", Operator: v1.NodeSelectorOpDoesNotExist, Values: values},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPreFilterRejectsDoesNotExistWithValues(t *testing.T) {
+	pod := doesNotExistPod([]string{"a"})
+
+	pl, _ := New(nil, nil)
+	status := pl.(*NodeAffinity).PreFilter(context.Background(), nil, pod)
+	if status.IsSuccess() {
+		t.Fatal("expected PreFilter to reject a DoesNotExist requirement carrying stray Values")
+	}
+	if status.Code() != framework.Error {
+		t.Errorf("expected framework.Error, got %v", status.Code())
+	}
+}
+
+func TestPreFilterAllowsDoesNotExistWithoutValues(t *testing.T) {
+	pod := doesNotExistPod(nil)
+
+	pl, _ := New(nil, nil)
+	status := pl.(*NodeAffinity).PreFilter(context.Background(), nil, pod)
+	if !status.IsSuccess() {
+		t.Errorf("expected PreFilter to succeed for a well-formed DoesNotExist requirement, got status: %v", status)
+	}
+}
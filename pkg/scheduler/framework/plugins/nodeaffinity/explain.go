@@ -0,0 +1,51 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/algorithm/predicates"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// TaintsNote is a fixed reminder attached to every MatchHint: node affinity and taints/tolerations are independent
+// scheduling concepts evaluated by separate plugins, so a matching affinity hint does not by itself mean the pod is
+// schedulable on the node.
+const TaintsNote = "node affinity is evaluated independently of taints/tolerations; see the TaintToleration plugin for taint-related rejections"
+
+// MatchHint is a structured, higher-level-tool-friendly explanation of why the NodeAffinity plugin did or did not
+// match a given node. It intentionally says nothing about taints: that hint is left to the caller to assemble from
+// other plugins' Explain-style hooks.
+type MatchHint struct {
+	// AffinityMatched reports whether the pod's node affinity/selector matched the node.
+	AffinityMatched bool
+	// Note documents that taints are out of scope for this hint.
+	Note string
+}
+
+// ExplainMatch is an optional diagnostic hook, intended for use by a higher-level "why didn't my pod schedule"
+// explain tool, that reports whether node affinity matched the given node without evaluating taints.
+func (pl *NodeAffinity) ExplainMatch(pod *v1.Pod, nodeInfo *schedulernodeinfo.NodeInfo) MatchHint {
+	matched := false
+	if node := nodeInfo.Node(); node != nil {
+		matched = predicates.PodMatchesNodeSelectorAndAffinityTerms(pod, node)
+	}
+	return MatchHint{
+		AffinityMatched: matched,
+		Note:            TaintsNote,
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+// failureKeyStateKey is the CycleState key nodeaffinity accumulates per-key Filter failure counts under, when
+// AggregateFailureReasons is enabled.
+const failureKeyStateKey framework.StateKey = "NodeAffinity/FailureKeyCounts"
+
+// failureKeyCounts counts, for this scheduling cycle, how many nodes failed Filter because of each missing
+// key=value pair.
+type failureKeyCounts map[string]int
+
+// Clone returns a copy of the counts, satisfying framework.StateData.
+func (c failureKeyCounts) Clone() framework.StateData {
+	clone := make(failureKeyCounts, len(c))
+	for k, v := range c {
+		clone[k] = v
+	}
+	return clone
+}
+
+// dominantMissingLabel returns the key=value the pod's first required In matchExpression (in its first
+// NodeSelectorTerm) demands that node doesn't have, and true, or "" and false if the pod has no such requirement to
+// blame (e.g. it uses a different operator, or the node actually satisfies it). This is necessarily a heuristic: a
+// pod can fail a node for more than one reason, and picking the first requirement of the first term is enough to
+// name a representative offender without evaluating the full OR-of-ANDs expression twice.
+func dominantMissingLabel(pod *v1.Pod, node *v1.Node) (string, bool) {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return "", false
+	}
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) == 0 {
+		return "", false
+	}
+	for _, expr := range terms[0].MatchExpressions {
+		if expr.Operator != v1.NodeSelectorOpIn || len(expr.Values) == 0 {
+			continue
+		}
+		satisfied := false
+		if nodeValue, ok := node.Labels[expr.Key]; ok {
+			for _, v := range expr.Values {
+				if v == nodeValue {
+					satisfied = true
+					break
+				}
+			}
+		}
+		if satisfied {
+			continue
+		}
+		return fmt.Sprintf("%s=%s", expr.Key, expr.Values[0]), true
+	}
+	return "", false
+}
+
+// recordFailureKey increments the CycleState's accumulated failure count for key. It's a no-op if state is nil,
+// which happens in tests that call Filter directly without a real scheduling cycle.
+func recordFailureKey(state *framework.CycleState, key string) {
+	if state == nil || key == "" {
+		return
+	}
+	state.Lock()
+	defer state.Unlock()
+
+	counts := failureKeyCounts{}
+	if existing, err := state.Read(failureKeyStateKey); err == nil {
+		if c, ok := existing.(failureKeyCounts); ok {
+			counts = c
+		}
+	}
+	counts[key]++
+	state.Write(failureKeyStateKey, counts)
+}
+
+// dominantFailureKey returns the most common key=value recorded by recordFailureKey this cycle, and its count, or ""
+// and 0 if nothing was recorded (e.g. AggregateFailureReasons was disabled, or every node passed).
+func dominantFailureKey(state *framework.CycleState) (string, int) {
+	if state == nil {
+		return "", 0
+	}
+	state.RLock()
+	defer state.RUnlock()
+
+	existing, err := state.Read(failureKeyStateKey)
+	if err != nil {
+		return "", 0
+	}
+	counts, ok := existing.(failureKeyCounts)
+	if !ok {
+		return "", 0
+	}
+	var topKey string
+	var topCount int
+	for k, v := range counts {
+		if v > topCount || (v == topCount && k < topKey) {
+			topKey, topCount = k, v
+		}
+	}
+	return topKey, topCount
+}
+
+// aggregatedFailureReason formats the PostFilter summary message naming the dominant offender, or "" if nothing was
+// recorded.
+func aggregatedFailureReason(state *framework.CycleState) string {
+	key, count := dominantFailureKey(state)
+	if key == "" {
+		return ""
+	}
+	return fmt.Sprintf("node(s) didn't have label %s (%d node(s))", key, count)
+}
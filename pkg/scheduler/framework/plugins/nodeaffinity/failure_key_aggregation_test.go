@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestPostFilterAggregatesDominantFailureReason(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1a")
+
+	nodeMissingZone := schedulernodeinfo.NewNodeInfo()
+	nodeMissingZone.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{}}})
+	nodeWrongZone := schedulernodeinfo.NewNodeInfo()
+	nodeWrongZone.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"zone": "us-west-1"}}})
+	nodeMatching := schedulernodeinfo.NewNodeInfo()
+	nodeMatching.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"zone": "us-east-1a"}}})
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), AggregateFailureReasons: true}
+	state := framework.NewCycleState()
+
+	pl.Filter(context.Background(), state, pod, nodeMissingZone)
+	pl.Filter(context.Background(), state, pod, nodeWrongZone)
+	pl.Filter(context.Background(), state, pod, nodeMatching)
+
+	reason := aggregatedFailureReason(state)
+	want := "node(s) didn't have label zone=us-east-1a (2 node(s))"
+	if reason != want {
+		t.Errorf("expected aggregated failure reason %q, got %q", want, reason)
+	}
+
+	status := pl.PostFilter(context.Background(), state, pod, nil, nil)
+	if !status.IsSuccess() {
+		t.Errorf("expected PostFilter to always succeed, got %v", status)
+	}
+}
+
+func TestFilterDoesNotAggregateFailureReasonsByDefault(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1a")
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{}}})
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	state := framework.NewCycleState()
+
+	pl.Filter(context.Background(), state, pod, nodeInfo)
+
+	if reason := aggregatedFailureReason(state); reason != "" {
+		t.Errorf("expected no aggregation with AggregateFailureReasons disabled, got %q", reason)
+	}
+}
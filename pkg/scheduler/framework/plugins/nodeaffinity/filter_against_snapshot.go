@@ -0,0 +1,45 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/algorithm/predicates"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/migration"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// FilterAgainstSnapshot runs the standard node selector/affinity predicate for pod against nodeName, looked up in
+// snapshot rather than a framework.FrameworkHandle's NodeInfoSnapshot. It's meant for custom schedulers that keep
+// their own map[string]*v1.Node snapshot and want this plugin's matching logic without adopting the scheduler
+// framework's NodeInfo type. Returns an Error status if nodeName isn't present in snapshot. Unlike Filter, it doesn't
+// consult any of NodeAffinity's optional fields (TopologyLabelEquivalence, NodeLabelAugmenter, AdvisoryMode, etc.);
+// it's the plain required-terms predicate only.
+func FilterAgainstSnapshot(pod *v1.Pod, nodeName string, snapshot map[string]*v1.Node) *framework.Status {
+	node, ok := snapshot[nodeName]
+	if !ok {
+		return framework.NewStatus(framework.Error, fmt.Sprintf("node %q not found in snapshot", nodeName))
+	}
+
+	nodeInfo := nodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+	_, reasons, err := predicates.PodMatchNodeSelector(pod, nil, nodeInfo)
+	return migration.PredicateResultToFrameworkStatus(reasons, err)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+func TestFilterAgainstSnapshotPresentNode(t *testing.T) {
+	pod := nodeSelectorPod("zone", "a")
+	snapshot := map[string]*v1.Node{
+		"node-1": {ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"zone": "a"}}},
+	}
+
+	status := FilterAgainstSnapshot(pod, "node-1", snapshot)
+	if !status.IsSuccess() {
+		t.Errorf("expected a matching node to pass, got status: %v", status)
+	}
+}
+
+func TestFilterAgainstSnapshotMismatchedNode(t *testing.T) {
+	pod := nodeSelectorPod("zone", "a")
+	snapshot := map[string]*v1.Node{
+		"node-1": {ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"zone": "b"}}},
+	}
+
+	status := FilterAgainstSnapshot(pod, "node-1", snapshot)
+	if status.IsSuccess() {
+		t.Error("expected a mismatched node to fail")
+	}
+}
+
+func TestFilterAgainstSnapshotAbsentNode(t *testing.T) {
+	pod := nodeSelectorPod("zone", "a")
+	snapshot := map[string]*v1.Node{}
+
+	status := FilterAgainstSnapshot(pod, "missing-node", snapshot)
+	if status.IsSuccess() {
+		t.Fatal("expected a node absent from the snapshot to fail")
+	}
+	if status.Code() != framework.Error {
+		t.Errorf("expected an Error status for an absent node, got code %v", status.Code())
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+var fuzzOperators = []v1.NodeSelectorOperator{
+	v1.NodeSelectorOpIn,
+	v1.NodeSelectorOpNotIn,
+	v1.NodeSelectorOpExists,
+	v1.NodeSelectorOpDoesNotExist,
+	v1.NodeSelectorOpGt,
+	v1.NodeSelectorOpLt,
+	"",              // malformed operator
+	"BogusOperator", // malformed operator
+}
+
+var fuzzKeys = []string{"", "foo", "kernel-version", "zone"}
+var fuzzValues = [][]string{nil, {}, {""}, {"bar"}, {"1", "2"}, {"invalid value: ___@#$%^"}}
+
+// randNodeSelectorRequirement returns a possibly-malformed NodeSelectorRequirement so Filter is exercised against
+// inputs the API server would normally reject, e.g. an empty Values slice for In, or an unknown operator.
+func randNodeSelectorRequirement(r *rand.Rand) v1.NodeSelectorRequirement {
+	return v1.NodeSelectorRequirement{
+		Key:      fuzzKeys[r.Intn(len(fuzzKeys))],
+		Operator: fuzzOperators[r.Intn(len(fuzzOperators))],
+		Values:   fuzzValues[r.Intn(len(fuzzValues))],
+	}
+}
+
+func randPod(r *rand.Rand) *v1.Pod {
+	numTerms := r.Intn(3)
+	var terms []v1.NodeSelectorTerm
+	// a nil terms slice is a valid (if unusual) input; only build one sometimes.
+	if numTerms > 0 || r.Intn(2) == 0 {
+		terms = make([]v1.NodeSelectorTerm, numTerms)
+		for i := range terms {
+			numReqs := r.Intn(3)
+			for j := 0; j < numReqs; j++ {
+				terms[i].MatchExpressions = append(terms[i].MatchExpressions, randNodeSelectorRequirement(r))
+			}
+		}
+	}
+
+	return &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: terms,
+					},
+				},
+			},
+		},
+	}
+}
+
+var fuzzLabelValues = []string{"", "bar", "1", "2", "0206"}
+
+func randNode(r *rand.Rand) *v1.Node {
+	labels := map[string]string{}
+	for i := 0; i < r.Intn(4); i++ {
+		labels[fuzzKeys[r.Intn(len(fuzzKeys))]] = fuzzLabelValues[r.Intn(len(fuzzLabelValues))]
+	}
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: labels}}
+}
+
+// TestFuzzFilterNeverPanics runs Filter against a large number of randomly generated (and often malformed) affinity
+// structures and node label maps, seeded deterministically for reproducibility, asserting only that it never panics.
+// Correctness of specific matches is covered by TestNodeAffinity.
+func TestFuzzFilterNeverPanics(t *testing.T) {
+	pl, _ := New(nil, nil)
+	filterPlugin := pl.(*NodeAffinity)
+
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < 2000; i++ {
+		pod := randPod(r)
+		node := randNode(r)
+
+		func() {
+			defer func() {
+				if rec := recover(); rec != nil {
+					t.Fatalf("Filter panicked on iteration %d: %v\npod: %#v\nnode labels: %v", i, rec, pod, node.Labels)
+				}
+			}()
+			nodeInfo := schedulernodeinfo.NewNodeInfo()
+			nodeInfo.SetNode(node)
+			filterPlugin.Filter(context.Background(), nil, pod, nodeInfo)
+		}()
+	}
+}
+
+// TestFuzzFilterNilNodeInfo covers the degenerate case of a NodeInfo with no backing Node object, which the seeded
+// table tests in node_affinity_test.go don't exercise directly.
+func TestFuzzFilterNilNodeInfo(t *testing.T) {
+	pl, _ := New(nil, nil)
+	filterPlugin := pl.(*NodeAffinity)
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			t.Fatalf("Filter panicked on a nil node: %v", rec)
+		}
+	}()
+	status := filterPlugin.Filter(context.Background(), nil, &v1.Pod{}, schedulernodeinfo.NewNodeInfo())
+	if status.IsSuccess() {
+		t.Error("expected filter to fail for a NodeInfo with no backing node, got success")
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// podRequiredTerms returns pod's effective required node-selector terms: NodeSelector, if any, is folded into every
+// term as extra In match expressions so it participates in the same OR-of-AND-groups shape as NodeAffinity. A pod
+// with neither returns a single term with no match expressions, matching every node.
+func podRequiredTerms(pod *v1.Pod) []v1.NodeSelectorTerm {
+	var extra []v1.NodeSelectorRequirement
+	for k, v := range pod.Spec.NodeSelector {
+		extra = append(extra, v1.NodeSelectorRequirement{Key: k, Operator: v1.NodeSelectorOpIn, Values: []string{v}})
+	}
+
+	var terms []v1.NodeSelectorTerm
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		if required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			terms = required.NodeSelectorTerms
+		}
+	}
+	if len(terms) == 0 {
+		terms = []v1.NodeSelectorTerm{{}}
+	}
+
+	merged := make([]v1.NodeSelectorTerm, 0, len(terms))
+	for _, term := range terms {
+		merged = append(merged, v1.NodeSelectorTerm{
+			MatchExpressions: append(append([]v1.NodeSelectorRequirement{}, term.MatchExpressions...), extra...),
+			MatchFields:      term.MatchFields,
+		})
+	}
+	return merged
+}
+
+// intersectTerm ANDs a and b's match expressions/fields into a single term, detecting the common "same key,
+// Operator In, disjoint values" conflict and narrowing to the intersecting values instead. ok is false when the
+// combination can never match any node.
+func intersectTerm(a, b v1.NodeSelectorTerm) (combined v1.NodeSelectorTerm, ok bool) {
+	exprs := append(append([]v1.NodeSelectorRequirement{}, a.MatchExpressions...), b.MatchExpressions...)
+
+	inValues := map[string][]string{}
+	for i, req := range exprs {
+		if req.Operator != v1.NodeSelectorOpIn {
+			continue
+		}
+		existing, seen := inValues[req.Key]
+		if !seen {
+			inValues[req.Key] = req.Values
+			continue
+		}
+		narrowed := intersectValues(existing, req.Values)
+		if len(narrowed) == 0 {
+			return v1.NodeSelectorTerm{}, false
+		}
+		inValues[req.Key] = narrowed
+		exprs[i].Values = narrowed
+	}
+
+	return v1.NodeSelectorTerm{
+		MatchExpressions: exprs,
+		MatchFields:      append(append([]v1.NodeSelectorRequirement{}, a.MatchFields...), b.MatchFields...),
+	}, true
+}
+
+func intersectValues(a, b []string) []string {
+	set := make(map[string]bool, len(a))
+	for _, v := range a {
+		set[v] = true
+	}
+	var out []string
+	for _, v := range b {
+		if set[v] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// IntersectNodeSelectors combines the required node-selector terms of every pod in a group with AND semantics: the
+// returned NodeSelector matches a node only if every pod's own requirements would. Because each pod's requirement
+// is itself an OR of terms, this expands to the cross product of terms across pods, dropping any combination whose
+// match expressions conflict (e.g. two incompatible required values for the same label key). This is meant for
+// pruning candidate nodes ahead of gang/batch scheduling, not for feeding directly into a single pod's Filter.
+//
+// If every combination conflicts, IntersectNodeSelectors returns a NodeSelector that can never match any real node,
+// together with an error describing the conflict.
+func IntersectNodeSelectors(pods []*v1.Pod) (*v1.NodeSelector, error) {
+	if len(pods) == 0 {
+		return &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{{}}}, nil
+	}
+
+	terms := podRequiredTerms(pods[0])
+	for _, pod := range pods[1:] {
+		var next []v1.NodeSelectorTerm
+		for _, a := range terms {
+			for _, b := range podRequiredTerms(pod) {
+				if combined, ok := intersectTerm(a, b); ok {
+					next = append(next, combined)
+				}
+			}
+		}
+		terms = next
+		if len(terms) == 0 {
+			break
+		}
+	}
+
+	if len(terms) == 0 {
+		return unsatisfiableNodeSelector(), fmt.Errorf("pod group has conflicting node affinity/selector requirements; no node could satisfy every pod")
+	}
+	return &v1.NodeSelector{NodeSelectorTerms: terms}, nil
+}
+
+// unsatisfiableNodeSelector returns a NodeSelector guaranteed not to match any real node: it requires a single
+// label key to simultaneously equal two disjoint made-up values.
+func unsatisfiableNodeSelector() *v1.NodeSelector {
+	return &v1.NodeSelector{
+		NodeSelectorTerms: []v1.NodeSelectorTerm{{
+			MatchExpressions: []v1.NodeSelectorRequirement{
+				{Key: "kubernetes.io/hostname", Operator: v1.NodeSelectorOpIn, Values: []string{"unsatisfiable-value-a"}},
+				{Key: "kubernetes.io/hostname", Operator: v1.NodeSelectorOpIn, Values: []string{"unsatisfiable-value-b"}},
+			},
+		}},
+	}
+}
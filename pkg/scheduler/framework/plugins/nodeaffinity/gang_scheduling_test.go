@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+)
+
+func podWithNodeSelector(kv map[string]string) *v1.Pod {
+	return &v1.Pod{Spec: v1.PodSpec{NodeSelector: kv}}
+}
+
+func selectorMatchesLabels(t *testing.T, selector *v1.NodeSelector, nodeLabels map[string]string) bool {
+	t.Helper()
+	for _, term := range selector.NodeSelectorTerms {
+		sel, err := v1helper.NodeSelectorRequirementsAsSelector(term.MatchExpressions)
+		if err != nil {
+			t.Fatalf("unexpected error building selector: %v", err)
+		}
+		if sel.Matches(labels.Set(nodeLabels)) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestIntersectNodeSelectorsCompatiblePods(t *testing.T) {
+	pods := []*v1.Pod{
+		podWithNodeSelector(map[string]string{"zone": "us-east-1"}),
+		podWithNodeSelector(map[string]string{"disk": "ssd"}),
+	}
+
+	selector, err := IntersectNodeSelectors(pods)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !selectorMatchesLabels(t, selector, map[string]string{"zone": "us-east-1", "disk": "ssd"}) {
+		t.Error("expected the intersection to match a node satisfying both pods' requirements")
+	}
+	if selectorMatchesLabels(t, selector, map[string]string{"zone": "us-east-1"}) {
+		t.Error("expected the intersection not to match a node missing the second pod's requirement")
+	}
+}
+
+func TestIntersectNodeSelectorsConflictingPods(t *testing.T) {
+	pods := []*v1.Pod{
+		podWithNodeSelector(map[string]string{"zone": "us-east-1"}),
+		podWithNodeSelector(map[string]string{"zone": "us-west-1"}),
+	}
+
+	selector, err := IntersectNodeSelectors(pods)
+	if err == nil {
+		t.Fatal("expected an error describing the conflicting requirements")
+	}
+	if selector == nil {
+		t.Fatal("expected a non-nil match-nothing selector alongside the error")
+	}
+	if selectorMatchesLabels(t, selector, map[string]string{"zone": "us-east-1"}) ||
+		selectorMatchesLabels(t, selector, map[string]string{"zone": "us-west-1"}) {
+		t.Error("expected the conflict selector to match no real node")
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/algorithm/predicates"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestFilterDefaultReasonMatchesLegacyPredicate(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"zone": "us-west-1"}}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl, _ := New(nil, nil)
+	status := pl.(*NodeAffinity).Filter(context.Background(), nil, pod, nodeInfo)
+
+	if status.Message() != predicates.ErrNodeSelectorNotMatch.GetReason() {
+		t.Errorf("expected the default reason to equal the legacy predicate reason %q, got %q", predicates.ErrNodeSelectorNotMatch.GetReason(), status.Message())
+	}
+}
+
+func TestFilterVerboseReasonsEnrichesMessage(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"zone": "us-west-1"}}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), VerboseReasons: true}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+
+	if !strings.HasPrefix(status.Message(), predicates.ErrNodeSelectorNotMatch.GetReason()) {
+		t.Errorf("expected the enriched reason to still start with the legacy reason, got %q", status.Message())
+	}
+	if !strings.Contains(status.Message(), "node-1") {
+		t.Errorf("expected the enriched reason to mention the node name, got %q", status.Message())
+	}
+}
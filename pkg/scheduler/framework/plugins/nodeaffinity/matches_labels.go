@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+)
+
+// MatchesLabels evaluates pod's legacy NodeSelector and required node affinity terms against an arbitrary label set,
+// without constructing a full Node or NodeInfo. nodeName is used for matchFields, exactly as
+// predicates.PodMatchNodeSelector uses the real node's name; it's the only node field a NodeSelectorRequirement can
+// currently reference. This is meant for lightweight simulation (e.g. capacity planning) that has a label set to
+// evaluate against but no real Node object on hand.
+//
+// Unlike predicates.PodMatchNodeSelector, which silently treats a malformed matchExpression/matchField as "this term
+// doesn't match" (so one bad term among several ORed ones doesn't sink the rest), MatchesLabels surfaces the first
+// such error to the caller, since a simulation is usually better served by a loud failure than a silently wrong
+// answer.
+func MatchesLabels(pod *v1.Pod, set labels.Set, nodeName string) (bool, error) {
+	if len(pod.Spec.NodeSelector) > 0 {
+		if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(set) {
+			return false, nil
+		}
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return true, nil
+	}
+	nodeAffinity := affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true, nil
+	}
+
+	nodeFields := fields.Set{schedulerapi.NodeFieldSelectorKeyNodeName: nodeName}
+	for _, term := range nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		matched, err := nodeSelectorTermMatchesLabels(term, set, nodeFields)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// nodeSelectorTermMatchesLabels reports whether a single (ANDed) NodeSelectorTerm matches set/nodeFields, mirroring
+// v1helper.MatchNodeSelectorTerms' per-term logic but returning a compilation error instead of treating it as a
+// non-match.
+func nodeSelectorTermMatchesLabels(term v1.NodeSelectorTerm, set labels.Set, nodeFields fields.Set) (bool, error) {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false, nil
+	}
+
+	if len(term.MatchExpressions) != 0 {
+		selector, err := v1helper.NodeSelectorRequirementsAsSelector(term.MatchExpressions)
+		if err != nil {
+			return false, err
+		}
+		if !selector.Matches(set) {
+			return false, nil
+		}
+	}
+
+	if len(term.MatchFields) != 0 {
+		fieldSelector, err := v1helper.NodeSelectorRequirementsAsFieldSelector(term.MatchFields)
+		if err != nil {
+			return false, err
+		}
+		if !fieldSelector.Matches(nodeFields) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+)
+
+// TestMatchesLabelsReproducesTableCases exercises MatchesLabels against several of the scenarios
+// predicates.PodMatchNodeSelector's own table test covers, to confirm the lighter labels.Set-based API agrees with
+// the NodeInfo-based one it's meant to substitute for.
+func TestMatchesLabelsReproducesTableCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      *v1.Pod
+		labels   map[string]string
+		nodeName string
+		wantOK   bool
+		wantErr  bool
+	}{
+		{
+			name:   "no selector or affinity matches everything",
+			pod:    &v1.Pod{},
+			wantOK: true,
+		},
+		{
+			name:   "nodeSelector matching label",
+			pod:    &v1.Pod{Spec: v1.PodSpec{NodeSelector: map[string]string{"foo": "bar"}}},
+			labels: map[string]string{"foo": "bar"},
+			wantOK: true,
+		},
+		{
+			name:   "nodeSelector not matching label",
+			pod:    &v1.Pod{Spec: v1.PodSpec{NodeSelector: map[string]string{"foo": "bar"}}},
+			labels: map[string]string{"foo": "baz"},
+			wantOK: false,
+		},
+		{
+			name: "invalid value in an affinity matchExpression surfaces an error",
+			pod: &v1.Pod{Spec: v1.PodSpec{Affinity: &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{
+						{MatchExpressions: []v1.NodeSelectorRequirement{
+							{Key: "foo", Operator: v1.NodeSelectorOpNotIn, Values: []string{"invalid value: ___@#$%^"}},
+						}},
+					},
+				},
+			}}}},
+			labels:  map[string]string{"foo": "bar"},
+			wantErr: true,
+		},
+		{
+			name: "matchFields using In operator that matches the existing node",
+			pod: &v1.Pod{Spec: v1.PodSpec{Affinity: &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{
+						{MatchFields: []v1.NodeSelectorRequirement{
+							{Key: schedulerapi.NodeFieldSelectorKeyNodeName, Operator: v1.NodeSelectorOpIn, Values: []string{"node_1"}},
+						}},
+					},
+				},
+			}}}},
+			nodeName: "node_1",
+			wantOK:   true,
+		},
+		{
+			name: "matchFields using In operator that does not match the existing node",
+			pod: &v1.Pod{Spec: v1.PodSpec{Affinity: &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{
+						{MatchFields: []v1.NodeSelectorRequirement{
+							{Key: schedulerapi.NodeFieldSelectorKeyNodeName, Operator: v1.NodeSelectorOpIn, Values: []string{"node_1"}},
+						}},
+					},
+				},
+			}}}},
+			nodeName: "node_2",
+			wantOK:   false,
+		},
+		{
+			name: "two terms: matchFields does not match, but matchExpressions matches",
+			pod: &v1.Pod{Spec: v1.PodSpec{Affinity: &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{
+						{MatchFields: []v1.NodeSelectorRequirement{
+							{Key: schedulerapi.NodeFieldSelectorKeyNodeName, Operator: v1.NodeSelectorOpIn, Values: []string{"node_1"}},
+						}},
+						{MatchExpressions: []v1.NodeSelectorRequirement{
+							{Key: "foo", Operator: v1.NodeSelectorOpIn, Values: []string{"bar"}},
+						}},
+					},
+				},
+			}}}},
+			nodeName: "node_2",
+			labels:   map[string]string{"foo": "bar"},
+			wantOK:   true,
+		},
+		{
+			name: "one term: both matchFields and matchExpressions match",
+			pod: &v1.Pod{Spec: v1.PodSpec{Affinity: &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{
+						{
+							MatchFields: []v1.NodeSelectorRequirement{
+								{Key: schedulerapi.NodeFieldSelectorKeyNodeName, Operator: v1.NodeSelectorOpIn, Values: []string{"node_1"}},
+							},
+							MatchExpressions: []v1.NodeSelectorRequirement{
+								{Key: "foo", Operator: v1.NodeSelectorOpIn, Values: []string{"bar"}},
+							},
+						},
+					},
+				},
+			}}}},
+			nodeName: "node_1",
+			labels:   map[string]string{"foo": "bar"},
+			wantOK:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := MatchesLabels(test.pod, labels.Set(test.labels), test.nodeName)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != test.wantOK {
+				t.Errorf("expected %v, got %v", test.wantOK, got)
+			}
+		})
+	}
+}
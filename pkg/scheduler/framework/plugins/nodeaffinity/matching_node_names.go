@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// MatchingNodeNames returns the names of every node in nodeInfos whose labels satisfy selector, reusing
+// nodeSelectorTermMatchesLabels (the per-term matcher behind MatchesLabels) with the same ORed-terms semantics
+// Filter applies. This is meant for admin tooling that wants to
+// answer "which nodes would a pod with this affinity land on?" without constructing a pod or running the plugin. A
+// malformed term is treated as a non-match for that term rather than aborting the whole query, the same tolerant
+// handling v1helper.MatchNodeSelectorTerms gives Filter.
+func MatchingNodeNames(selector *v1.NodeSelector, nodeInfos []*schedulernodeinfo.NodeInfo) []string {
+	if selector == nil {
+		return nil
+	}
+
+	var names []string
+	for _, nodeInfo := range nodeInfos {
+		node := nodeInfo.Node()
+		if node == nil {
+			continue
+		}
+		nodeFields := fields.Set{schedulerapi.NodeFieldSelectorKeyNodeName: node.Name}
+		set := labels.Set(node.Labels)
+		for _, term := range selector.NodeSelectorTerms {
+			matched, err := nodeSelectorTermMatchesLabels(term, set, nodeFields)
+			if err != nil || !matched {
+				continue
+			}
+			names = append(names, node.Name)
+			break
+		}
+	}
+	return names
+}
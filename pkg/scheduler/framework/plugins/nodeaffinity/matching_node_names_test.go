@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"sort"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestMatchingNodeNames(t *testing.T) {
+	nodes := []*v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "east-1", Labels: map[string]string{"zone": "us-east-1"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "east-2", Labels: map[string]string{"zone": "us-east-1"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "west-1", Labels: map[string]string{"zone": "us-west-1"}}},
+	}
+	var nodeInfos []*schedulernodeinfo.NodeInfo
+	for _, node := range nodes {
+		nodeInfo := schedulernodeinfo.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		nodeInfos = append(nodeInfos, nodeInfo)
+	}
+
+	selector := &v1.NodeSelector{
+		NodeSelectorTerms: []v1.NodeSelectorTerm{
+			{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"us-east-1"}}}},
+		},
+	}
+
+	got := MatchingNodeNames(selector, nodeInfos)
+	sort.Strings(got)
+	want := []string{"east-1", "east-2"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("MatchingNodeNames() = %v, want %v", got, want)
+	}
+}
+
+func TestMatchingNodeNamesReturnsNilForNilSelector(t *testing.T) {
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}})
+
+	if got := MatchingNodeNames(nil, []*schedulernodeinfo.NodeInfo{nodeInfo}); got != nil {
+		t.Errorf("expected nil for a nil selector, got %v", got)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	outcomeSkip    = "skip"
+	outcomeProceed = "proceed"
+)
+
+var preFilterOutcome = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Subsystem:      "scheduler",
+		Name:           "nodeaffinity_prefilter_outcome_total",
+		Help:           "Number of NodeAffinity PreFilter calls, by outcome. 'skip' is a pod with neither NodeAffinity nor a nodeSelector, which Filter treats as an unconditional match; 'proceed' is a pod whose terms Filter must actually evaluate per node.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"outcome"})
+
+var termMatchLatency = metrics.NewHistogramVec(
+	&metrics.HistogramOpts{
+		Subsystem:      "scheduler",
+		Name:           "nodeaffinity_term_match_duration_seconds",
+		Help:           "Latency of evaluating a single required NodeSelectorTerm against a node, labeled by the term's index in RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms. Only recorded when the MeasureTermLatency plugin arg is enabled; helps operators spot a pathological term (e.g. a huge NotIn list) that would otherwise be averaged away inside Filter's overall latency.",
+		Buckets:        metrics.ExponentialBuckets(0.00001, 2, 20),
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"term_index"})
+
+var requiredTermsPerPod = metrics.NewHistogram(
+	&metrics.HistogramOpts{
+		Subsystem:      "scheduler",
+		Name:           "nodeaffinity_required_terms_per_pod",
+		Help:           "Number of RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms compiled per pod evaluated in PreFilter, regardless of whether the pod has any node affinity at all (0 terms). Gives capacity planners visibility into how complex affinity expressions are across the fleet.",
+		Buckets:        []float64{0, 1, 2, 3, 5, 8, 13, 21},
+		StabilityLevel: metrics.ALPHA,
+	})
+
+var registerMetricsOnce sync.Once
+
+// Register registers this package's metrics with the legacy registry. Safe to call more than once.
+func Register() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(preFilterOutcome)
+		legacyregistry.MustRegister(termMatchLatency)
+		legacyregistry.MustRegister(requiredTermsPerPod)
+	})
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestPreFilterOutcomeMetric(t *testing.T) {
+	Register()
+	preFilterOutcome.Reset()
+	pl, _ := New(nil, nil)
+	na := pl.(*NodeAffinity)
+
+	na.PreFilter(context.Background(), nil, &v1.Pod{})
+	if got := testutil.ToFloat64(preFilterOutcome.CounterVec.WithLabelValues(outcomeSkip)); got != 1 {
+		t.Errorf("expected the skip counter to be 1 for a pod with no affinity, got %v", got)
+	}
+
+	na.PreFilter(context.Background(), nil, nodeSelectorPod("zone", "us-east-1"))
+	if got := testutil.ToFloat64(preFilterOutcome.CounterVec.WithLabelValues(outcomeProceed)); got != 1 {
+		t.Errorf("expected the proceed counter to be 1 for a pod with affinity, got %v", got)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// hasNodeAffinity reports whether pod already specifies its own node affinity.
+func hasNodeAffinity(pod *v1.Pod) bool {
+	return pod.Spec.Affinity != nil && pod.Spec.Affinity.NodeAffinity != nil
+}
+
+// NamespaceAffinityMergeStrategy controls how a namespace's default NodeAffinity combines with a pod's own, when
+// the pod specifies one, in applyNamespaceDefaultAffinity. It has no effect on a pod with no NodeAffinity of its
+// own: that case always gets the namespace default outright, under either strategy.
+type NamespaceAffinityMergeStrategy int
+
+const (
+	// ReplaceOnConflict has a pod's own NodeAffinity, if it specifies one, entirely replace the namespace default;
+	// the default is only ever substituted in for a pod that specifies none at all. This is the zero value and the
+	// default strategy, since a pod that specifies its own NodeAffinity is never affected by a namespace default
+	// being introduced or changed.
+	ReplaceOnConflict NamespaceAffinityMergeStrategy = iota
+	// MergeAnd has a pod's own required terms combine with the namespace default's required terms, so a node must
+	// satisfy both, and concatenates their preferred terms. Intended for multi-tenant setups that want a namespace
+	// default to be a floor a pod's own affinity narrows further, rather than something the pod can opt out of by
+	// specifying any affinity of its own.
+	MergeAnd
+)
+
+// applyNamespaceDefaultAffinity returns pod unchanged if namespaceDefaults is nil, or there's no default registered
+// for pod's namespace. Otherwise it returns a copy of pod with the namespace's default NodeAffinity combined with
+// any the pod already specifies, per strategy, leaving the original pod untouched.
+func applyNamespaceDefaultAffinity(pod *v1.Pod, namespaceDefaults map[string]*v1.NodeAffinity, strategy NamespaceAffinityMergeStrategy) *v1.Pod {
+	defaultAffinity, ok := namespaceDefaults[pod.Namespace]
+	if !ok {
+		return pod
+	}
+
+	if !hasNodeAffinity(pod) {
+		podCopy := pod.DeepCopy()
+		if podCopy.Spec.Affinity == nil {
+			podCopy.Spec.Affinity = &v1.Affinity{}
+		}
+		podCopy.Spec.Affinity.NodeAffinity = defaultAffinity
+		return podCopy
+	}
+
+	if strategy != MergeAnd {
+		return pod
+	}
+
+	podCopy := pod.DeepCopy()
+	podCopy.Spec.Affinity.NodeAffinity = mergeNodeAffinityAnd(podCopy.Spec.Affinity.NodeAffinity, defaultAffinity)
+	return podCopy
+}
+
+// mergeNodeAffinityAnd combines pod's and defaultAffinity's NodeAffinity so the result requires both: preferred
+// terms are simply concatenated, and required terms are cross-multiplied term-by-term, since a NodeSelectorTerm's
+// MatchExpressions/MatchFields are ANDed but the term list itself is ORed, so ANDing two OR-of-AND selectors
+// requires distributing one over the other.
+func mergeNodeAffinityAnd(pod, defaultAffinity *v1.NodeAffinity) *v1.NodeAffinity {
+	merged := &v1.NodeAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: append(
+			append([]v1.PreferredSchedulingTerm{}, pod.PreferredDuringSchedulingIgnoredDuringExecution...),
+			defaultAffinity.PreferredDuringSchedulingIgnoredDuringExecution...,
+		),
+	}
+
+	podRequired := pod.RequiredDuringSchedulingIgnoredDuringExecution
+	defaultRequired := defaultAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	switch {
+	case podRequired == nil:
+		merged.RequiredDuringSchedulingIgnoredDuringExecution = defaultRequired
+	case defaultRequired == nil:
+		merged.RequiredDuringSchedulingIgnoredDuringExecution = podRequired
+	default:
+		var terms []v1.NodeSelectorTerm
+		for _, a := range podRequired.NodeSelectorTerms {
+			for _, b := range defaultRequired.NodeSelectorTerms {
+				terms = append(terms, v1.NodeSelectorTerm{
+					MatchExpressions: append(append([]v1.NodeSelectorRequirement{}, a.MatchExpressions...), b.MatchExpressions...),
+					MatchFields:      append(append([]v1.NodeSelectorRequirement{}, a.MatchFields...), b.MatchFields...),
+				})
+			}
+		}
+		merged.RequiredDuringSchedulingIgnoredDuringExecution = &v1.NodeSelector{NodeSelectorTerms: terms}
+	}
+
+	return merged
+}
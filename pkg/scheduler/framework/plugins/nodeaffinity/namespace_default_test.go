@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func requiredNodeAffinity(key string, values ...string) *v1.NodeAffinity {
+	return &v1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{
+				{MatchExpressions: []v1.NodeSelectorRequirement{
+					{Key: key, Operator: v1.NodeSelectorOpIn, Values: values},
+				}},
+			},
+		},
+	}
+}
+
+func TestFilterAppliesNamespaceDefaultAffinity(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "payments"}}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{
+		selectorCache: newSelectorCache(defaultSelectorCacheSize),
+		NamespaceDefaultAffinity: map[string]*v1.NodeAffinity{
+			"payments": requiredNodeAffinity("team", "payments"),
+		},
+	}
+
+	t.Run("pod without its own affinity gets the namespace default", func(t *testing.T) {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "payments"}}
+		status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+		if !status.IsSuccess() {
+			t.Errorf("expected the namespace default affinity to match, got status: %v", status)
+		}
+		if pod.Spec.Affinity != nil {
+			t.Error("expected the original pod to be left untouched")
+		}
+	})
+
+	t.Run("namespace without a default is unaffected", func(t *testing.T) {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "other"}}
+		status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+		if !status.IsSuccess() {
+			t.Errorf("expected a pod with no affinity and no namespace default to match any node, got status: %v", status)
+		}
+	})
+
+	t.Run("pod with its own affinity is untouched even with a namespace default", func(t *testing.T) {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "payments"},
+			Spec:       v1.PodSpec{Affinity: &v1.Affinity{NodeAffinity: requiredNodeAffinity("team", "other-team")}},
+		}
+		status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+		if status.IsSuccess() {
+			t.Error("expected the pod's own affinity to take precedence over the namespace default")
+		}
+	})
+}
+
+func TestFilterMergesNamespaceDefaultAffinityUnderMergeAnd(t *testing.T) {
+	pl := &NodeAffinity{
+		selectorCache: newSelectorCache(defaultSelectorCacheSize),
+		NamespaceDefaultAffinity: map[string]*v1.NodeAffinity{
+			"payments": requiredNodeAffinity("team", "payments"),
+		},
+		NamespaceAffinityMergeStrategy: MergeAnd,
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "payments"},
+		Spec:       v1.PodSpec{Affinity: &v1.Affinity{NodeAffinity: requiredNodeAffinity("zone", "us-east-1")}},
+	}
+
+	t.Run("a node satisfying only the pod's own term is rejected", func(t *testing.T) {
+		nodeInfo := schedulernodeinfo.NewNodeInfo()
+		nodeInfo.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"zone": "us-east-1"}}})
+		status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+		if status.IsSuccess() {
+			t.Error("expected MergeAnd to also require the namespace default's term")
+		}
+	})
+
+	t.Run("a node satisfying both the pod's and the namespace default's term matches", func(t *testing.T) {
+		nodeInfo := schedulernodeinfo.NewNodeInfo()
+		nodeInfo.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"zone": "us-east-1", "team": "payments"}}})
+		status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+		if !status.IsSuccess() {
+			t.Errorf("expected a node satisfying both terms to match, got status: %v", status)
+		}
+	})
+
+	if pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0].Key != "zone" {
+		t.Error("expected the original pod to be left untouched by merging")
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"container/list"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// defaultNegativeResultCacheSize bounds the number of (selector, node label set) pairs remembered as a non-match.
+const defaultNegativeResultCacheSize = 1024
+
+// negativeResultKey identifies a required-selector-against-node-labels match attempt. Both fields are hashes rather
+// than the raw content so the cache's per-entry footprint stays constant regardless of selector or label size; a
+// node's label set changing (even by one key) changes nodeLabelHash, which is exactly the invalidation this cache
+// needs, since a stale negative result for the node's old labels simply becomes an unrelated, harmless cache miss.
+type negativeResultKey struct {
+	selectorHash  uint64
+	nodeLabelHash uint64
+}
+
+// negativeResultCache is a small bounded LRU set recording (selectorHash, nodeLabelHash) pairs that previously
+// failed to match, so a repeat Filter call for the same pod's required terms against a node whose labels haven't
+// changed can skip recomputing the match. It never caches a positive match: a false positive served from a stale
+// cache would incorrectly admit a node, while a false negative only costs a redundant recomputation. Safe for
+// concurrent use.
+type negativeResultCache struct {
+	lock     sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[negativeResultKey]*list.Element
+}
+
+// newNegativeResultCache returns an empty negativeResultCache bounded to capacity entries.
+func newNegativeResultCache(capacity int) *negativeResultCache {
+	return &negativeResultCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[negativeResultKey]*list.Element),
+	}
+}
+
+// isKnownNegative reports whether key was previously recorded as a non-match, marking it most-recently-used if so.
+func (c *negativeResultCache) isKnownNegative(key negativeResultKey) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.ll.MoveToFront(elem)
+	return true
+}
+
+// recordNegative marks key as a non-match, evicting the least-recently-used entry if capacity is exceeded.
+func (c *negativeResultCache) recordNegative(key negativeResultKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	elem := c.ll.PushFront(key)
+	c.items[key] = elem
+
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(negativeResultKey))
+	}
+}
+
+// len returns the number of entries currently cached. Intended for tests.
+func (c *negativeResultCache) len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.ll.Len()
+}
+
+// requiredSelectorHash hashes the pod's required node selector/affinity terms, so pods with the same required
+// matching shape share a cache key regardless of pod identity.
+func requiredSelectorHash(pod *v1.Pod) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%v", pod.Spec.NodeSelector)
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		fmt.Fprintf(h, "%v", affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+	}
+	return h.Sum64()
+}
+
+// nodeLabelHash hashes node's labels in a deterministic (sorted-key) order, so the same label set always hashes the
+// same way regardless of map iteration order, and any change to the label set (add, remove, or value change)
+// changes the hash.
+func nodeLabelHash(node *v1.Node) uint64 {
+	h := fnv.New64a()
+	if node == nil {
+		return h.Sum64()
+	}
+
+	keys := make([]string, 0, len(node.Labels))
+	for k := range node.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\n", k, node.Labels[k])
+	}
+	return h.Sum64()
+}
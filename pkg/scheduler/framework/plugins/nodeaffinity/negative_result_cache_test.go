@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+func TestFilterCachesNegativeMatchAcrossCalls(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1")
+	nodeInfo := nodeInfoWithLabels("node-1", map[string]string{"zone": "us-west-1"})
+
+	pl := &NodeAffinity{
+		selectorCache:        newSelectorCache(defaultSelectorCacheSize),
+		negativeCache:        newNegativeResultCache(defaultNegativeResultCacheSize),
+		CacheNegativeMatches: true,
+	}
+
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Fatal("expected the mismatched node to be rejected")
+	}
+	if pl.negativeCache.len() != 1 {
+		t.Fatalf("expected the negative result to be recorded, got %d entries", pl.negativeCache.len())
+	}
+
+	key := negativeResultKey{selectorHash: requiredSelectorHash(pod), nodeLabelHash: nodeLabelHash(nodeInfo.Node())}
+	if !pl.negativeCache.isKnownNegative(key) {
+		t.Error("expected the cache to already know this (selector, node label) pair is a non-match")
+	}
+
+	// A second Filter call for the same pod/node should hit the cache and still reject, without changing the
+	// cache's size.
+	status = pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Fatal("expected the cached negative result to still reject the node")
+	}
+	if status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected UnschedulableAndUnresolvable, got %v", status.Code())
+	}
+	if pl.negativeCache.len() != 1 {
+		t.Errorf("expected the cache size to stay at 1 entry after a repeat call, got %d", pl.negativeCache.len())
+	}
+}
+
+func TestFilterNegativeCacheInvalidatedByLabelChange(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1")
+	nodeInfo := nodeInfoWithLabels("node-1", map[string]string{"zone": "us-west-1"})
+
+	pl := &NodeAffinity{
+		selectorCache:        newSelectorCache(defaultSelectorCacheSize),
+		negativeCache:        newNegativeResultCache(defaultNegativeResultCacheSize),
+		CacheNegativeMatches: true,
+	}
+
+	if status := pl.Filter(context.Background(), nil, pod, nodeInfo); status.IsSuccess() {
+		t.Fatal("expected the initial mismatch to be rejected")
+	}
+
+	// The node's labels change to now satisfy the pod's node selector; the stale cache entry for the old label set
+	// must not cause this Filter call to be rejected.
+	relabeled := nodeInfoWithLabels("node-1", map[string]string{"zone": "us-east-1"})
+	status := pl.Filter(context.Background(), nil, pod, relabeled)
+	if !status.IsSuccess() {
+		t.Fatalf("expected the relabeled node to now match, got: %v", status)
+	}
+	if pl.negativeCache.len() != 1 {
+		t.Errorf("expected only the original negative entry to remain cached, got %d", pl.negativeCache.len())
+	}
+}
+
+func TestNegativeResultCacheBoundedEviction(t *testing.T) {
+	c := newNegativeResultCache(2)
+	c.recordNegative(negativeResultKey{selectorHash: 1, nodeLabelHash: 1})
+	c.recordNegative(negativeResultKey{selectorHash: 2, nodeLabelHash: 2})
+	c.recordNegative(negativeResultKey{selectorHash: 3, nodeLabelHash: 3})
+
+	if c.len() != 2 {
+		t.Fatalf("expected the cache to stay bounded at 2 entries, got %d", c.len())
+	}
+	if c.isKnownNegative(negativeResultKey{selectorHash: 1, nodeLabelHash: 1}) {
+		t.Error("expected the least-recently-used entry to have been evicted")
+	}
+	if !c.isKnownNegative(negativeResultKey{selectorHash: 3, nodeLabelHash: 3}) {
+		t.Error("expected the most recently added entry to still be cached")
+	}
+}
@@ -18,9 +18,14 @@ package nodeaffinity
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
 	"k8s.io/kubernetes/pkg/scheduler/algorithm/predicates"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/migration"
 	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
@@ -28,9 +33,204 @@ import (
 )
 
 // NodeAffinity is a plugin that checks if a pod node selector matches the node label.
-type NodeAffinity struct{}
+type NodeAffinity struct {
+	handle        framework.FrameworkHandle
+	selectorCache *selectorCache
+	negativeCache *negativeResultCache
+
+	// TopologyLabelEquivalence maps a node label key to an equivalent key that should be treated as satisfying the
+	// same matchExpression, e.g. the legacy failure-domain labels and their topology.kubernetes.io replacements.
+	// Defaults to DefaultTopologyLabelEquivalence; set to nil to disable.
+	TopologyLabelEquivalence map[string]string
+
+	// NodeConditionPseudoLabels, when true, has Filter expose each of a node's Status.Conditions as a pseudo-label
+	// (e.g. "node.condition/Ready=True"), so a matchExpression can target a condition the same way it targets any
+	// other label. Off by default; a real label a node already carries under one of these keys always wins.
+	NodeConditionPseudoLabels bool
+
+	// Tracer, if set, receives span events recording the number of preferred terms compiled and one event per node
+	// scored, naming that node, for debugging scheduling latency. Nil, the default, makes this a no-op.
+	Tracer SpanRecorder
+
+	// VerboseReasons, when true, appends the node name to a Filter failure's Status message instead of returning it
+	// unchanged. Leave this false, the default, if anything parses Status.Message() for the exact legacy string
+	// (predicates.ErrNodeSelectorNotMatch.GetReason()); some older consumers still do.
+	VerboseReasons bool
+
+	// NamespaceDefaultAffinity maps a namespace to the NodeAffinity applied, in Filter, to a pod in that namespace
+	// that doesn't specify its own. Whether a pod that DOES specify its own is also affected is controlled by
+	// NamespaceAffinityMergeStrategy. Nil, the default, disables this behavior entirely.
+	NamespaceDefaultAffinity map[string]*v1.NodeAffinity
+
+	// NamespaceAffinityMergeStrategy selects how a namespace's default NodeAffinity combines with a pod's own when
+	// both are present. Defaults to ReplaceOnConflict, leaving a pod that specifies any NodeAffinity of its own
+	// entirely unaffected by the namespace default.
+	NamespaceAffinityMergeStrategy NamespaceAffinityMergeStrategy
+
+	// ScoringMode selects how Score weighs a pod's PreferredDuringSchedulingIgnoredDuringExecution terms. Defaults
+	// to ScoringModeWeightedSum, the standard per-term-weight behavior.
+	ScoringMode ScoringMode
+
+	// NodeLabelAugmenter, if set, is consulted by Filter for every node to compute additional virtual labels (e.g.
+	// capabilities surfaced by device plugins rather than real node labels) merged with node.Labels before
+	// evaluating matchExpressions. Nil, the default, disables augmentation entirely.
+	NodeLabelAugmenter NodeLabelAugmenter
+
+	// LogCycleSummary, when true, has Filter accumulate its pass/fail outcome in CycleState and PostFilter log a
+	// single aggregate line for the cycle, for debugging bursty scheduling without a log line per node. Off by
+	// default, since accumulation takes CycleState's lock on every Filter call.
+	LogCycleSummary bool
+
+	// RelaxedValueValidation, when true, has Filter compare a required matchExpression's values against node labels
+	// (including any from NodeLabelAugmenter) literally, instead of through a labels.Selector. A labels.Selector
+	// rejects any value over 63 chars as an invalid label value, which otherwise makes a matchExpression unmatchable
+	// against an augmented value sourced from a custom resource rather than a real node label. Off by default.
+	RelaxedValueValidation bool
+
+	// WildcardValues, when true, has Filter treat an In/NotIn matchExpression or NodeSelector value ending in '*' as
+	// a prefix match against the node's label value (e.g. "zone-a-*" matches "zone-a-rack-3"), for clusters whose
+	// node labels encode structured values pods want to select on a prefix of. Like RelaxedValueValidation, this
+	// bypasses labels.Selector entirely, since '*' isn't a valid label-value character and would otherwise be
+	// rejected outright. Off by default; exact matching is unaffected either way.
+	WildcardValues bool
+
+	// PenaltyTerms complements the positive scoring done by ScoringMode/tiered required terms: a node matching a
+	// term here has the term's weight subtracted from its score by Score, clamped at framework.MinNodeScore so a
+	// heavily penalized node still scores no lower than an unmatched one. Nil, the default, applies no penalty.
+	PenaltyTerms []WeightedNodeSelectorTerm
+
+	// AdvisoryMode, when true, has Filter admit a node that fails the pod's required node selector/affinity terms
+	// instead of rejecting it, logging a warning, so Score can apply a matching penalty rather than removing the node
+	// from consideration entirely. Meant for a soft degradation window (e.g. most nodes temporarily missing a
+	// just-introduced required label) where scheduling something imperfectly beats not scheduling at all. Off by
+	// default, which keeps required terms strictly enforced by Filter as before.
+	AdvisoryMode bool
+
+	// TransientLabelGapGracePeriod, if positive, has Filter downgrade a mismatch against a completely unlabeled node
+	// created within this long ago from UnschedulableAndUnresolvable (the default for a node selector mismatch) to
+	// Unschedulable with a retry-after hint, on the theory that kubelet just hasn't reported its labels yet rather
+	// than the node genuinely lacking them. Zero, the default, leaves every mismatch UnschedulableAndUnresolvable.
+	TransientLabelGapGracePeriod time.Duration
+
+	// nowFn returns the current time and is overridden by tests. Defaults to time.Now.
+	nowFn func() time.Time
+
+	// AllowedSelectorKeys, when non-empty, has Filter return UnschedulableAndUnresolvable for a pod whose
+	// NodeSelector or NodeAffinity terms reference any node label key outside this set, for clusters that want to
+	// restrict which labels pods are allowed to select on. Empty, the default, applies no restriction.
+	AllowedSelectorKeys []string
+
+	// StrictAllTerms, when true, has Filter require every one of the pod's required NodeSelectorTerms to match
+	// (AND) instead of the API's usual any-one-of (OR) semantics, for policies that compose NodeSelectorTerms as
+	// independent, defense-in-depth constraints rather than alternatives. Off by default, which preserves the API's
+	// documented OR behavior.
+	StrictAllTerms bool
+
+	// MeasureTermLatency, when true, has Filter separately time evaluating each of the pod's required
+	// NodeSelectorTerms and record the result in the nodeaffinity_term_match_duration_seconds histogram, labeled by
+	// the term's index, for tuning complex affinity expressions where one term (e.g. a huge NotIn list) dominates
+	// the rest. Off by default, since timing every term on every Filter call isn't free.
+	MeasureTermLatency bool
+
+	// NodeNameSource, if set, has Filter evaluate a required matchFields on the node-name key against the node
+	// annotation named by this field instead of node.Name (metadata.name), for clusters that reference nodes by an
+	// external ID carried in an annotation. Empty, the default, matches against node.Name as the API documents.
+	NodeNameSource string
+
+	// AggregateFailureReasons, when true, has Filter record which key=value pair a required In matchExpression
+	// blamed for each node's failure in CycleState, and PostFilter log a summary naming the most common offender
+	// across the whole cycle (e.g. "node(s) didn't have label topology.kubernetes.io/zone=us-east-1a"), instead of
+	// leaving every node with only its own generic "node(s) didn't match node selector" reason. Off by default,
+	// since accumulation takes CycleState's lock on every failing Filter call.
+	AggregateFailureReasons bool
+
+	// ShortCircuitAbsentRequiredLabels, when true, has PreFilter check the pod's first required In matchExpression
+	// against every node in the handle's NodeInfoSnapshot; if no node carries any of the referenced values, PreFilter
+	// rejects the pod outright as UnschedulableAndUnresolvable with a precise reason instead of letting Filter reject
+	// every node individually. Falls back to the normal per-node behavior if the snapshot isn't available (e.g. the
+	// plugin was constructed without a handle, as in unit tests). Off by default.
+	ShortCircuitAbsentRequiredLabels bool
+
+	// RequiredPresentKeys, when non-empty, has Filter reject any node missing one of these label keys, regardless of
+	// its value and regardless of whether the pod's own node selector/affinity references it at all, for cluster
+	// policies that mandate every node carry certain keys (e.g. a zone label used by other components). Empty, the
+	// default, applies no restriction.
+	RequiredPresentKeys []string
+
+	// AllowProviderIDMatchField, when true, has Filter additionally resolve a required matchFields requirement on
+	// the "spec.providerID" key against node.Spec.ProviderID, for cloud-integration tooling that wants to target
+	// nodes by their cloud provider ID rather than metadata.name. Any other matchFields key is unaffected. Off by
+	// default, since the API server's own validation doesn't recognize this key and most clusters have no use for
+	// it.
+	AllowProviderIDMatchField bool
+
+	// TopologyConsistency, keyed by the value of the topology.kubernetes.io/region label, lists the
+	// topology.kubernetes.io/zone values that are valid for that region; a node carrying both labels whose zone
+	// isn't listed under its region is rejected by Filter as UnschedulableAndUnresolvable, regardless of the pod's
+	// own requirements, on the theory that a node with an internally inconsistent region/zone combination is
+	// misconfigured and shouldn't be trusted for placement decisions of any kind. A node missing either label, or
+	// whose region has no entry here, is unaffected. Nil, the default, applies no restriction.
+	TopologyConsistency map[string][]string
+
+	// PreferSpecificPreferredTerms, when true, has NormalizeScore break ties among nodes that Score gave the same
+	// raw score by nudging down every node in the tie except the one(s) matching the most specific (most
+	// matchExpressions) preferred term, so a node satisfying a narrowly-targeted preference is favored over one that
+	// only happens to match a broad one at the same weight. It never changes the relative order of nodes that didn't
+	// already tie. Off by default, which leaves Score's output as the final word and ScoreExtensions nil.
+	PreferSpecificPreferredTerms bool
+
+	// CacheNegativeMatches, when true, has Filter's default (non-relaxed, non-strict, non-NodeNameSource,
+	// non-AllowProviderIDMatchField) required-term matching path remember a (selector, node label set) pair that
+	// failed to match, keyed by hashes of each, and skip recomputing the match on a later Filter call for the same
+	// pod's required terms against a node whose labels haven't changed since. A node's labels changing invalidates
+	// its entries automatically, since that changes the node label hash. The cache is bounded and never stores a
+	// positive match, so a full cache never causes an incorrect admission, only a redundant recomputation. Off by
+	// default.
+	CacheNegativeMatches bool
+
+	// AttemptWeightDecayFactor, when ScoringMode is ScoringModeWeightedSumWithAttemptDecay, shrinks each preferred
+	// term's effective weight by this fraction for every scheduling attempt already recorded against the pod (see
+	// SchedulingAttemptAnnotation), compounding geometrically: after n attempts a term's weight is multiplied by
+	// (1-AttemptWeightDecayFactor)^n. Zero, the default, disables decay entirely -- weights stay at their configured
+	// value regardless of attempt count -- even if ScoringMode selects WeightedSumWithAttemptDecay. Has no effect
+	// under any other ScoringMode.
+	AttemptWeightDecayFactor float64
+
+	// DecisionSink, if set, has Filter record the outcome of every required-term evaluation it makes -- match or
+	// mismatch, and why -- by calling RecordDecision after the evaluation completes but before AdvisoryMode or
+	// VerboseReasons have a chance to alter the outcome, for compliance regimes that need an auditable trail beyond
+	// metrics/logs. A node rejected earlier by RequiredPresentKeys, TopologyConsistency or AllowedSelectorKeys is not
+	// recorded. Nil, the default, records nothing.
+	DecisionSink DecisionSink
+
+	// NodeMetadataProvider, if set, is consulted by Filter for every node to fetch additional labels from a source
+	// outside the Node object itself (e.g. a scheduler extender tracking capabilities the API server never sees).
+	// Results are cached per node and refreshed asynchronously; a lookup that doesn't complete within
+	// NodeMetadataTimeout falls back to the last cached result, or to node.Labels alone if there is none yet. Nil,
+	// the default, disables this entirely.
+	NodeMetadataProvider NodeMetadataProvider
+
+	// NodeMetadataTimeout bounds how long Filter waits for a NodeMetadataProvider lookup before falling back.
+	// Zero, the default, uses defaultNodeMetadataTimeout. Has no effect if NodeMetadataProvider is nil.
+	NodeMetadataTimeout time.Duration
+
+	// nodeMetadataCache holds the most recent successful NodeMetadataProvider result per node, consulted on a
+	// timed-out lookup. Populated lazily; nil until New or a test constructs one.
+	nodeMetadataCache *nodeMetadataCache
+
+	// ValueTransformers, keyed by node label key, is applied to both the pod's required value(s) and the node's
+	// label value for that key before an In/NotIn/equality comparison is made, so differently formatted sources
+	// (e.g. a pod value that's always lowercase against a node label that isn't) can still match. A key with no
+	// entry compares literally, exactly as before. Non-empty, this activates the same literal comparison path as
+	// RelaxedValueValidation/WildcardValues, since a labels.Selector has no way to apply a transform. Nil, the
+	// default, applies no restriction.
+	ValueTransformers map[string]func(string) string
+}
 
 var _ = framework.FilterPlugin(&NodeAffinity{})
+var _ = framework.PostFilterPlugin(&NodeAffinity{})
+var _ = framework.ScorePlugin(&NodeAffinity{})
+var _ = framework.PreFilterPlugin(&NodeAffinity{})
 
 // Name is the name of the plugin used in the plugin registry and configurations.
 const Name = "NodeAffinity"
@@ -40,13 +240,315 @@ func (pl *NodeAffinity) Name() string {
 	return Name
 }
 
-// Filter invoked at the filter extension point.
-func (pl *NodeAffinity) Filter(ctx context.Context, _ *framework.CycleState, pod *v1.Pod, nodeInfo *nodeinfo.NodeInfo) *framework.Status {
+// PreFilter compiles and caches the pod's required/preferred node affinity matchers, keyed by pod UID and
+// resourceVersion, so repeated scheduling attempts for the same pod shape (e.g. Job retries) skip recompilation.
+// It first rejects a pod outright if any requirement uses Exists or DoesNotExist while also specifying Values,
+// which the API documents as invalid but which some callers silently ignore instead of enforcing. If
+// ShortCircuitAbsentRequiredLabels is enabled, it then rejects a pod whose first required In matchExpression
+// references a value no node in the snapshot carries at all. Every other compilation failure is left for
+// Filter/Score to surface, so PreFilter otherwise always succeeds.
+func (pl *NodeAffinity) PreFilter(ctx context.Context, _ *framework.CycleState, pod *v1.Pod) *framework.Status {
+	if req, invalid := invalidExistsValues(pod); invalid {
+		return framework.NewStatus(framework.Error, existsValueGuardReason(req))
+	}
+
+	if pl.ShortCircuitAbsentRequiredLabels && pl.handle != nil {
+		if key, values, ok := firstRequiredInTerm(pod); ok {
+			if nodeInfoMap := pl.handle.NodeInfoSnapshot().NodeInfoMap; len(nodeInfoMap) > 0 && noNodeHasAnyValue(nodeInfoMap, key, values) {
+				return framework.NewStatus(framework.UnschedulableAndUnresolvable, absentLabelReason(key, values))
+			}
+		}
+	}
+
+	compiled := compileAffinity(pod)
+	pl.selectorCache.add(selectorCacheKey{uid: pod.UID, resourceVersion: pod.ResourceVersion}, compiled)
+
+	klog.V(4).Infof("nodeaffinity: pod %q/%q compiled required selector: %s", pod.Namespace, pod.Name, requiredSelectorString(compiled.requiredSelectors))
+
+	preferredTerms := 0
+	requiredTerms := 0
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		preferredTerms = len(affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+		if req := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; req != nil {
+			requiredTerms = len(req.NodeSelectorTerms)
+		}
+	}
+	recordEvent(pl.Tracer, "nodeaffinity.PreFilter", map[string]interface{}{"preferredTermsCompiled": preferredTerms})
+	requiredTermsPerPod.Observe(float64(requiredTerms))
+
+	outcome := outcomeProceed
+	if !hasNodeAffinity(pod) && len(pod.Spec.NodeSelector) == 0 {
+		outcome = outcomeSkip
+	}
+	preFilterOutcome.WithLabelValues(outcome).Inc()
+
+	return nil
+}
+
+// PreFilterExtensions of the PreFilter plugin.
+func (pl *NodeAffinity) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+// Filter invoked at the filter extension point. Unless StrictAllTerms is set, required terms remain ORed exactly as
+// before, regardless of any TierAnnotationPrefix annotations: tiering only changes how a match is weighted at the
+// Score extension point, not whether the pod fits the node.
+func (pl *NodeAffinity) Filter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *nodeinfo.NodeInfo) *framework.Status {
+	if patched := nodeWithEquivalentTopologyLabels(nodeInfo.Node(), pl.TopologyLabelEquivalence); patched != nodeInfo.Node() {
+		patchedInfo := nodeinfo.NewNodeInfo()
+		patchedInfo.SetNode(patched)
+		nodeInfo = patchedInfo
+	}
+	if patched := nodeWithAugmentedLabels(nodeInfo.Node(), pl.NodeLabelAugmenter); patched != nodeInfo.Node() {
+		patchedInfo := nodeinfo.NewNodeInfo()
+		patchedInfo.SetNode(patched)
+		nodeInfo = patchedInfo
+	}
+	if patched := nodeWithConditionPseudoLabels(nodeInfo.Node(), pl.NodeConditionPseudoLabels); patched != nodeInfo.Node() {
+		patchedInfo := nodeinfo.NewNodeInfo()
+		patchedInfo.SetNode(patched)
+		nodeInfo = patchedInfo
+	}
+	if pl.NodeMetadataProvider != nil && pl.nodeMetadataCache != nil {
+		if patched := nodeWithExtenderMetadata(nodeInfo.Node(), pl.NodeMetadataProvider, pl.nodeMetadataCache, pl.NodeMetadataTimeout); patched != nodeInfo.Node() {
+			patchedInfo := nodeinfo.NewNodeInfo()
+			patchedInfo.SetNode(patched)
+			nodeInfo = patchedInfo
+		}
+	}
+	pod = applyNamespaceDefaultAffinity(pod, pl.NamespaceDefaultAffinity, pl.NamespaceAffinityMergeStrategy)
+
+	if key, missing := missingRequiredPresentKey(nodeInfo.Node(), pl.RequiredPresentKeys); missing {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, requiredPresentKeyReason(key))
+	}
+
+	if region, zone, inconsistent := inconsistentTopology(nodeInfo.Node(), pl.TopologyConsistency); inconsistent {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, topologyConsistencyReason(region, zone))
+	}
+
+	if key, disallowed := disallowedSelectorKey(pod, pl.AllowedSelectorKeys); disallowed {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, allowedSelectorKeyReason(key))
+	}
+
+	if pl.MeasureTermLatency {
+		observeTermMatchLatency(pod, nodeInfo.Node())
+	}
+
+	usesDefaultMatching := !pl.StrictAllTerms && pl.NodeNameSource == "" && !pl.AllowProviderIDMatchField &&
+		!pl.RelaxedValueValidation && !pl.WildcardValues && len(pl.ValueTransformers) == 0
+	var negativeCacheKey negativeResultKey
+	cacheable := pl.CacheNegativeMatches && pl.negativeCache != nil && usesDefaultMatching
+	knownNegative := cacheable && func() bool {
+		negativeCacheKey = negativeResultKey{selectorHash: requiredSelectorHash(pod), nodeLabelHash: nodeLabelHash(nodeInfo.Node())}
+		return pl.negativeCache.isKnownNegative(negativeCacheKey)
+	}()
+
+	var reasons []predicates.PredicateFailureReason
+	var err error
+	switch {
+	case knownNegative:
+		reasons = []predicates.PredicateFailureReason{predicates.ErrNodeSelectorNotMatch}
+	case pl.StrictAllTerms:
+		var matched bool
+		matched, err = requiredTermsMatchAllStrict(pod, nodeInfo.Node())
+		if err == nil && !matched {
+			reasons = []predicates.PredicateFailureReason{predicates.ErrNodeSelectorNotMatch}
+		}
+	case pl.NodeNameSource != "":
+		var matched bool
+		matched, err = requiredTermsMatchWithNodeNameSource(pod, nodeInfo.Node(), pl.NodeNameSource)
+		if err == nil && !matched {
+			reasons = []predicates.PredicateFailureReason{predicates.ErrNodeSelectorNotMatch}
+		}
+	case pl.AllowProviderIDMatchField:
+		var matched bool
+		matched, err = requiredTermsMatchWithProviderIDField(pod, nodeInfo.Node())
+		if err == nil && !matched {
+			reasons = []predicates.PredicateFailureReason{predicates.ErrNodeSelectorNotMatch}
+		}
+	case pl.RelaxedValueValidation || pl.WildcardValues || len(pl.ValueTransformers) > 0:
+		if !matchesNodeSelectorAndAffinityRelaxed(pod, nodeInfo.Node(), pl.WildcardValues, pl.ValueTransformers) {
+			reasons = []predicates.PredicateFailureReason{predicates.ErrNodeSelectorNotMatch}
+		}
+	default:
+		_, reasons, err = predicates.PodMatchNodeSelector(pod, nil, nodeInfo)
+	}
+	if cacheable && !knownNegative && err == nil && len(reasons) > 0 {
+		pl.negativeCache.recordNegative(negativeCacheKey)
+	}
+	if pl.DecisionSink != nil {
+		pl.DecisionSink.RecordDecision(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, nodeInfo.Node().Name, err == nil && len(reasons) == 0, decisionReason(reasons, err))
+	}
+	status := migration.PredicateResultToFrameworkStatus(reasons, err)
+	if pl.AdvisoryMode && err == nil && status != nil && !status.IsSuccess() {
+		nodeName := ""
+		if node := nodeInfo.Node(); node != nil {
+			nodeName = node.Name
+		}
+		klog.Warningf("nodeaffinity: pod %q/%q failed required node affinity terms against node %q; admitting under AdvisoryMode so Score can apply a penalty instead of rejecting it", pod.Namespace, pod.Name, nodeName)
+		status = nil
+	}
+	if pl.VerboseReasons && err == nil && status != nil && !status.IsSuccess() {
+		nodeName := ""
+		if node := nodeInfo.Node(); node != nil {
+			nodeName = node.Name
+		}
+		status = framework.NewStatus(status.Code(), fmt.Sprintf("%s (node %q)", status.Message(), nodeName))
+	}
+	status = relaxIfTransientLabelGap(status, nodeInfo.Node(), pl.TransientLabelGapGracePeriod, pl.now())
+	if pl.LogCycleSummary {
+		recordCycleOutcome(state, status.IsSuccess())
+	}
+	if pl.AggregateFailureReasons && status != nil && !status.IsSuccess() {
+		if key, ok := dominantMissingLabel(pod, nodeInfo.Node()); ok {
+			recordFailureKey(state, key)
+		}
+	}
+	return status
+}
+
+// PostFilter logs a single aggregate line summarizing this cycle's Filter outcomes, when LogCycleSummary is
+// enabled, and a second line naming the most common failing key, when AggregateFailureReasons is enabled. It always
+// succeeds: this plugin is purely informational at this extension point.
+func (pl *NodeAffinity) PostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodes []*v1.Node, filteredNodesStatuses framework.NodeToStatusMap) *framework.Status {
+	if pl.LogCycleSummary {
+		summary := readCycleSummary(state)
+		klog.V(3).Infof("nodeaffinity: pod %q/%q cycle summary: %d node(s) passed, %d node(s) failed", pod.Namespace, pod.Name, summary.passed, summary.failed)
+	}
+	if pl.AggregateFailureReasons {
+		if reason := aggregatedFailureReason(state); reason != "" {
+			klog.V(2).Infof("nodeaffinity: pod %q/%q: %s", pod.Namespace, pod.Name, reason)
+		}
+	}
+	return nil
+}
+
+// Score invoked at the score extension point. Pods that opted into tiered required terms are scored by
+// ScoreTieredRequiredTerms; otherwise, if ScoringMode is ScoringModeCountUnmatchedPreferred,
+// ScoringModeCountMatchedRequirements, or ScoringModeWeightedSumWithAttemptDecay, the pod's real preferred terms are
+// scored by scoreCountUnmatchedPreferred, scoreCountMatchedRequirements, or scoreWeightedSumWithDecay respectively.
+// All other pods score 0 here. As in Filter, the node is
+// first merged with any NodeLabelAugmenter output, so a preferred term (or PenaltyTerms entry) can match against a
+// virtual, computed label the same way a required one can. Whatever that base score is, a node matching a
+// PenaltyTerms entry then has the term's weight subtracted from it, and under AdvisoryMode a node that fails the
+// pod's required terms is penalized down to framework.MinNodeScore instead of the Filter rejection AdvisoryMode
+// suppressed, so it never scores higher than a node that actually satisfies them.
+func (pl *NodeAffinity) Score(ctx context.Context, _ *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	defer recordEvent(pl.Tracer, "nodeaffinity.Score", map[string]interface{}{"node": nodeName})
+
+	hasTieredTerms := HasTieredRequiredTerms(pod)
+	preferredTerms := pl.countedPreferredTerms(pod)
+	needsNode := hasTieredTerms || len(preferredTerms) > 0 || len(pl.PenaltyTerms) > 0 || pl.AdvisoryMode
+	if !needsNode {
+		return 0, nil
+	}
+
+	nodeInfo, exist := pl.handle.NodeInfoSnapshot().NodeInfoMap[nodeName]
+	if !exist {
+		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("node %q does not exist in NodeInfoSnapshot", nodeName))
+	}
+	node := nodeWithAugmentedLabels(nodeInfo.Node(), pl.NodeLabelAugmenter)
+
+	var score int64
+	if hasTieredTerms {
+		if _, softTiers := ParseTieredRequiredTerms(pod); len(softTiers) > 0 {
+			score = ScoreTieredRequiredTerms(node, softTiers)
+		}
+	} else if len(preferredTerms) > 0 {
+		switch pl.ScoringMode {
+		case ScoringModeCountMatchedRequirements:
+			score = scoreCountMatchedRequirements(node, preferredTerms)
+		case ScoringModeWeightedSumWithAttemptDecay:
+			score = scoreWeightedSumWithDecay(node, preferredTerms, schedulingAttempts(pod), pl.AttemptWeightDecayFactor)
+		default:
+			score = scoreCountUnmatchedPreferred(node, preferredTerms)
+		}
+	}
+
+	if pl.AdvisoryMode && !pl.requiredTermsMatch(pod, node) {
+		score = framework.MinNodeScore
+	}
+
+	if len(pl.PenaltyTerms) > 0 {
+		score -= scorePenaltyTerms(node, pl.PenaltyTerms)
+		if score < framework.MinNodeScore {
+			score = framework.MinNodeScore
+		}
+	}
+
+	return score, nil
+}
+
+// countedPreferredTerms returns the pod's PreferredDuringSchedulingIgnoredDuringExecution terms when ScoringMode is
+// ScoringModeCountUnmatchedPreferred, ScoringModeCountMatchedRequirements, or ScoringModeWeightedSumWithAttemptDecay,
+// or nil otherwise.
+func (pl *NodeAffinity) countedPreferredTerms(pod *v1.Pod) []v1.PreferredSchedulingTerm {
+	switch pl.ScoringMode {
+	case ScoringModeCountUnmatchedPreferred, ScoringModeCountMatchedRequirements, ScoringModeWeightedSumWithAttemptDecay:
+	default:
+		return nil
+	}
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return nil
+	}
+	return affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+}
+
+// requiredTermsMatch reports whether pod's required node selector/affinity terms match node, using the same
+// matching mode (relaxed/wildcard vs the strict labels.Selector path) Filter uses, so AdvisoryMode's Score penalty
+// agrees with what Filter would otherwise have rejected on.
+func (pl *NodeAffinity) requiredTermsMatch(pod *v1.Pod, node *v1.Node) bool {
+	if pl.RelaxedValueValidation || pl.WildcardValues || len(pl.ValueTransformers) > 0 {
+		return matchesNodeSelectorAndAffinityRelaxed(pod, node, pl.WildcardValues, pl.ValueTransformers)
+	}
+	nodeInfo := nodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
 	_, reasons, err := predicates.PodMatchNodeSelector(pod, nil, nodeInfo)
-	return migration.PredicateResultToFrameworkStatus(reasons, err)
+	return err == nil && len(reasons) == 0
+}
+
+// decisionReason renders the outcome of a required-term evaluation as a single string for DecisionSink: err's
+// message if the evaluation itself failed, each failure reason's GetReason() joined by "; " if it completed but
+// didn't match, or "" for a clean match.
+func decisionReason(reasons []predicates.PredicateFailureReason, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if len(reasons) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		parts = append(parts, reason.GetReason())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// now returns the current time, using nowFn if a test has overridden it.
+func (pl *NodeAffinity) now() time.Time {
+	if pl.nowFn != nil {
+		return pl.nowFn()
+	}
+	return time.Now()
+}
+
+// ScoreExtensions of the Score plugin. Returns pl itself, so NormalizeScore runs, only when PreferSpecificPreferredTerms
+// is enabled; otherwise nil, preserving the previous behavior of leaving Score's raw output untouched.
+func (pl *NodeAffinity) ScoreExtensions() framework.ScoreExtensions {
+	if pl.PreferSpecificPreferredTerms {
+		return pl
+	}
+	return nil
 }
 
 // New initializes a new plugin and returns it.
-func New(_ *runtime.Unknown, _ framework.FrameworkHandle) (framework.Plugin, error) {
-	return &NodeAffinity{}, nil
+func New(_ *runtime.Unknown, h framework.FrameworkHandle) (framework.Plugin, error) {
+	return &NodeAffinity{
+		handle:                   h,
+		selectorCache:            newSelectorCache(defaultSelectorCacheSize),
+		negativeCache:            newNegativeResultCache(defaultNegativeResultCacheSize),
+		nodeMetadataCache:        newNodeMetadataCache(),
+		TopologyLabelEquivalence: DefaultTopologyLabelEquivalence,
+	}, nil
 }
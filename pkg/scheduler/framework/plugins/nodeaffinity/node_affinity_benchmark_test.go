@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// benchmarkNode returns a node with numLabels labels, all but one ("zone", which every benchmark pod selects on)
+// irrelevant filler, so Filter has to walk past them the way it would on a real, densely-labeled node.
+func benchmarkNode(numLabels int) *schedulernodeinfo.NodeInfo {
+	labels := make(map[string]string, numLabels)
+	labels["zone"] = "us-east-1"
+	for i := 1; i < numLabels; i++ {
+		labels[fmt.Sprintf("filler-label-%d", i)] = fmt.Sprintf("value-%d", i)
+	}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "bench-node", Labels: labels}})
+	return nodeInfo
+}
+
+// benchmarkPod returns a pod with numTerms ORed required NodeSelectorTerms, each a single "zone" In matchExpression
+// listing numValues candidate values (all but the last a miss, so evaluation runs to the end of the list).
+func benchmarkPod(numTerms, numValues int) *v1.Pod {
+	if numTerms == 0 {
+		return &v1.Pod{}
+	}
+	values := make([]string, numValues)
+	for i := 0; i < numValues-1; i++ {
+		values[i] = fmt.Sprintf("us-west-%d", i)
+	}
+	values[numValues-1] = "us-east-1"
+
+	terms := make([]v1.NodeSelectorTerm, numTerms)
+	for i := range terms {
+		terms[i] = v1.NodeSelectorTerm{
+			MatchExpressions: []v1.NodeSelectorRequirement{
+				{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: values},
+			},
+		}
+	}
+
+	return &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: terms},
+				},
+			},
+		},
+	}
+}
+
+// BenchmarkFilter covers Filter across pods with 0/1/5 required terms, nodes carrying 10/100/1000 labels, and
+// In-list sizes of varying length, to catch regressions in the matcher's per-call cost.
+func BenchmarkFilter(b *testing.B) {
+	for _, numTerms := range []int{0, 1, 5} {
+		for _, numLabels := range []int{10, 100, 1000} {
+			for _, numValues := range []int{1, 10, 100} {
+				if numTerms == 0 && numValues != 1 {
+					continue // numValues is meaningless with no terms; avoid redundant runs.
+				}
+				pod := benchmarkPod(numTerms, numValues)
+				nodeInfo := benchmarkNode(numLabels)
+				pl, err := New(nil, nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				plugin := pl.(*NodeAffinity)
+
+				name := fmt.Sprintf("terms=%d/labels=%d/values=%d", numTerms, numLabels, numValues)
+				b.Run(name, func(b *testing.B) {
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						plugin.Filter(context.Background(), nil, pod, nodeInfo)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// nodeConditionLabelPrefix prefixes the pseudo-label key nodeWithConditionPseudoLabels synthesizes for each of a
+// node's conditions, e.g. "node.condition/Ready" for the Ready condition.
+const nodeConditionLabelPrefix = "node.condition/"
+
+// nodeWithConditionPseudoLabels returns node unchanged if enabled is false or node has no conditions, or a deep
+// copy whose Labels have been extended with one pseudo-label per entry in node.Status.Conditions, keyed
+// nodeConditionLabelPrefix+condition type (e.g. "node.condition/Ready") and valued with the condition's Status
+// ("True", "False", or "Unknown"), letting a matchExpression target a condition the same way it targets any other
+// label. A real label node already carries under one of these synthesized keys always wins. The returned Node is
+// safe to mutate further by the caller; it does not alias node.Labels.
+func nodeWithConditionPseudoLabels(node *v1.Node, enabled bool) *v1.Node {
+	if node == nil || !enabled || len(node.Status.Conditions) == 0 {
+		return node
+	}
+
+	additions := map[string]string{}
+	for _, condition := range node.Status.Conditions {
+		key := nodeConditionLabelPrefix + string(condition.Type)
+		if _, alreadySet := node.Labels[key]; alreadySet {
+			continue
+		}
+		additions[key] = string(condition.Status)
+	}
+	if len(additions) == 0 {
+		return node
+	}
+
+	merged := make(map[string]string, len(node.Labels)+len(additions))
+	for k, v := range node.Labels {
+		merged[k] = v
+	}
+	for k, v := range additions {
+		merged[k] = v
+	}
+
+	nodeCopy := node.DeepCopy()
+	nodeCopy.Labels = merged
+	return nodeCopy
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func nodeWithReadyCondition(status v1.ConditionStatus) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func TestFilterMatchesReadyConditionPseudoLabel(t *testing.T) {
+	pod := nodeSelectorPod("node.condition/Ready", "True")
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(nodeWithReadyCondition(v1.ConditionTrue))
+
+	pl := &NodeAffinity{
+		selectorCache:             newSelectorCache(defaultSelectorCacheSize),
+		NodeConditionPseudoLabels: true,
+	}
+	if status := pl.Filter(context.Background(), nil, pod, nodeInfo); !status.IsSuccess() {
+		t.Errorf("expected the pod to match a Ready node, got status: %v", status)
+	}
+}
+
+func TestFilterFailsAgainstNotReadyConditionPseudoLabel(t *testing.T) {
+	pod := nodeSelectorPod("node.condition/Ready", "True")
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(nodeWithReadyCondition(v1.ConditionFalse))
+
+	pl := &NodeAffinity{
+		selectorCache:             newSelectorCache(defaultSelectorCacheSize),
+		NodeConditionPseudoLabels: true,
+	}
+	if status := pl.Filter(context.Background(), nil, pod, nodeInfo); status.IsSuccess() {
+		t.Error("expected the pod not to match a NotReady node")
+	}
+}
+
+func TestFilterIgnoresConditionPseudoLabelsByDefault(t *testing.T) {
+	pod := nodeSelectorPod("node.condition/Ready", "True")
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(nodeWithReadyCondition(v1.ConditionTrue))
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	if status := pl.Filter(context.Background(), nil, pod, nodeInfo); status.IsSuccess() {
+		t.Error("expected NodeConditionPseudoLabels to default to off, so the pseudo-label should not be visible")
+	}
+}
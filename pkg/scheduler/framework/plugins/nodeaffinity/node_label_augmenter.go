@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// NodeLabelAugmenter computes additional virtual labels for a node, for capabilities that are computed (e.g. by
+// device plugins) rather than set as real node labels. Both Filter and Score evaluate matchExpressions against
+// node.Labels merged with this output, so a NodeSelectorTerm can match against either, whether required or
+// preferred.
+type NodeLabelAugmenter func(node *v1.Node) map[string]string
+
+// nodeWithAugmentedLabels returns node unchanged if augmenter is nil or contributes no new labels, otherwise a
+// DeepCopy() with the augmented labels merged in. A node's own label always wins over an augmented one of the same
+// key, so a device plugin can't shadow a real label.
+func nodeWithAugmentedLabels(node *v1.Node, augmenter NodeLabelAugmenter) *v1.Node {
+	if node == nil || augmenter == nil {
+		return node
+	}
+	augmented := augmenter(node)
+	if len(augmented) == 0 {
+		return node
+	}
+
+	needsPatch := false
+	for k := range augmented {
+		if _, exists := node.Labels[k]; !exists {
+			needsPatch = true
+			break
+		}
+	}
+	if !needsPatch {
+		return node
+	}
+
+	patched := node.DeepCopy()
+	if patched.Labels == nil {
+		patched.Labels = map[string]string{}
+	}
+	for k, v := range augmented {
+		if _, exists := patched.Labels[k]; !exists {
+			patched.Labels[k] = v
+		}
+	}
+	return patched
+}
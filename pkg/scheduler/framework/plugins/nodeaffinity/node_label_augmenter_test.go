@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestFilterMatchesViaAugmentedLabel(t *testing.T) {
+	pod := nodeSelectorPod("gpu.example.com/model", "a100")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{
+		selectorCache: newSelectorCache(defaultSelectorCacheSize),
+		NodeLabelAugmenter: func(n *v1.Node) map[string]string {
+			return map[string]string{"gpu.example.com/model": "a100"}
+		},
+	}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Errorf("expected a pod requiring an augmented-only label to match, got status: %v", status)
+	}
+	if node.Labels != nil {
+		t.Error("expected the original node not to be mutated")
+	}
+}
+
+func TestFilterAugmenterDisabledByDefault(t *testing.T) {
+	pod := nodeSelectorPod("gpu.example.com/model", "a100")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Error("expected filter to fail with no augmenter configured")
+	}
+}
+
+func TestNodeWithAugmentedLabelsDoesNotOverwriteExisting(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"gpu.example.com/model": "explicit-value"}}}
+
+	patched := nodeWithAugmentedLabels(node, func(n *v1.Node) map[string]string {
+		return map[string]string{"gpu.example.com/model": "a100"}
+	})
+	if patched.Labels["gpu.example.com/model"] != "explicit-value" {
+		t.Errorf("expected the node's own label to be preserved, got %q", patched.Labels["gpu.example.com/model"])
+	}
+	if patched != node {
+		t.Error("expected the node not to be copied when the augmenter contributes nothing new")
+	}
+}
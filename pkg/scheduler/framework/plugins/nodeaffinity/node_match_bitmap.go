@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// BuildNodeMatchBitmap reports, for each entry in nodeInfos, whether pod's node selector and required node affinity
+// match that node's labels. The pod's affinity is compiled once (reusing the same selectorCache PreFilter populates,
+// so a repeated query for the same pod skips recompilation) rather than once per node, for callers like cluster
+// autoscaler simulations that repeatedly ask this question against a fixed pod. The result aligns to nodeInfos'
+// order.
+//
+// As in Filter, a required matchExpression that fails to compile (e.g. an unsupported operator) is treated as
+// non-matching for that term rather than surfaced as an error.
+func (pl *NodeAffinity) BuildNodeMatchBitmap(pod *v1.Pod, nodeInfos []*nodeinfo.NodeInfo) []bool {
+	key := selectorCacheKey{uid: pod.UID, resourceVersion: pod.ResourceVersion}
+	compiled, ok := pl.selectorCache.get(key)
+	if !ok {
+		compiled = compileAffinity(pod)
+		pl.selectorCache.add(key, compiled)
+	}
+
+	result := make([]bool, len(nodeInfos))
+	for i, nodeInfo := range nodeInfos {
+		node := nodeInfo.Node()
+		if node == nil {
+			continue
+		}
+		result[i] = matchesCompiledAffinity(pod, node, compiled)
+	}
+	return result
+}
+
+// matchesCompiledAffinity reports whether node satisfies pod's node selector and the required selectors compiled
+// into compiled. A pod with no RequiredDuringSchedulingIgnoredDuringExecution matches every node's affinity, mirroring
+// predicates.PodMatchesNodeSelectorAndAffinityTerms treating a nil RequiredDuringSchedulingIgnoredDuringExecution as
+// matching everything.
+func matchesCompiledAffinity(pod *v1.Pod, node *v1.Node, compiled compiledAffinity) bool {
+	if len(pod.Spec.NodeSelector) > 0 {
+		selector := labels.SelectorFromSet(pod.Spec.NodeSelector)
+		if !selector.Matches(labels.Set(node.Labels)) {
+			return false
+		}
+	}
+
+	if affinity := pod.Spec.Affinity; affinity == nil || affinity.NodeAffinity == nil ||
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	nodeLabels := labels.Set(node.Labels)
+	for _, selector := range compiled.requiredSelectors {
+		if selector.Matches(nodeLabels) {
+			return true
+		}
+	}
+	return false
+}
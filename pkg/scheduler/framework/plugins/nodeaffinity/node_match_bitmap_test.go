@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func nodeInfoWithLabels(name string, labels map[string]string) *schedulernodeinfo.NodeInfo {
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}})
+	return nodeInfo
+}
+
+func TestBuildNodeMatchBitmap(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1")
+	nodeInfos := []*schedulernodeinfo.NodeInfo{
+		nodeInfoWithLabels("match", map[string]string{"zone": "us-east-1"}),
+		nodeInfoWithLabels("mismatch", map[string]string{"zone": "us-west-1"}),
+		nodeInfoWithLabels("no-labels", nil),
+	}
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	bitmap := pl.BuildNodeMatchBitmap(pod, nodeInfos)
+
+	want := []bool{true, false, false}
+	if len(bitmap) != len(want) {
+		t.Fatalf("expected a bitmap of length %d, got %d", len(want), len(bitmap))
+	}
+	for i := range want {
+		if bitmap[i] != want[i] {
+			t.Errorf("index %d (%s): expected %v, got %v", i, nodeInfos[i].Node().Name, want[i], bitmap[i])
+		}
+	}
+
+	if pl.selectorCache.len() != 1 {
+		t.Errorf("expected the pod's affinity to be compiled and cached once, got %d cache entries", pl.selectorCache.len())
+	}
+}
+
+func TestBuildNodeMatchBitmapNoAffinityMatchesEveryNode(t *testing.T) {
+	pod := &v1.Pod{}
+	nodeInfos := []*schedulernodeinfo.NodeInfo{
+		nodeInfoWithLabels("a", nil),
+		nodeInfoWithLabels("b", map[string]string{"zone": "us-east-1"}),
+	}
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	bitmap := pl.BuildNodeMatchBitmap(pod, nodeInfos)
+
+	for i, matched := range bitmap {
+		if !matched {
+			t.Errorf("index %d: expected a pod with no affinity to match every node", i)
+		}
+	}
+}
+
+func TestBuildNodeMatchBitmapReusesPreFilterCacheEntry(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1")
+	pod.UID = "pod-uid"
+	pod.ResourceVersion = "1"
+	nodeInfos := []*schedulernodeinfo.NodeInfo{nodeInfoWithLabels("match", map[string]string{"zone": "us-east-1"})}
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	pl.PreFilter(nil, nil, pod)
+	if pl.selectorCache.len() != 1 {
+		t.Fatalf("expected PreFilter to populate the cache, got %d entries", pl.selectorCache.len())
+	}
+
+	bitmap := pl.BuildNodeMatchBitmap(pod, nodeInfos)
+	if !bitmap[0] {
+		t.Error("expected the node to match")
+	}
+	if pl.selectorCache.len() != 1 {
+		t.Errorf("expected BuildNodeMatchBitmap to reuse PreFilter's cache entry rather than add a new one, got %d entries", pl.selectorCache.len())
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// defaultNodeMetadataTimeout is used by nodeWithExtenderMetadata when NodeMetadataTimeout is unset.
+const defaultNodeMetadataTimeout = 50 * time.Millisecond
+
+// NodeMetadataProvider fetches additional labels for a node from a source outside the Node object itself, e.g. a
+// scheduler extender that tracks capabilities the API server never sees. A call is expected to block for as long as
+// the underlying lookup takes; nodeWithExtenderMetadata is what enforces a timeout around it.
+type NodeMetadataProvider interface {
+	NodeMetadata(nodeName string) (map[string]string, error)
+}
+
+// nodeMetadataCache remembers the last successful NodeMetadataProvider result per node, so a Filter call that times
+// out waiting for a fresh lookup can still use the previous one instead of falling all the way back to node.Labels.
+type nodeMetadataCache struct {
+	mu    sync.Mutex
+	items map[string]map[string]string
+}
+
+func newNodeMetadataCache() *nodeMetadataCache {
+	return &nodeMetadataCache{items: map[string]map[string]string{}}
+}
+
+func (c *nodeMetadataCache) get(nodeName string) (map[string]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	metadata, ok := c.items[nodeName]
+	return metadata, ok
+}
+
+func (c *nodeMetadataCache) set(nodeName string, metadata map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[nodeName] = metadata
+}
+
+// nodeWithExtenderMetadata returns node unchanged if provider is nil. Otherwise, it kicks off a NodeMetadata lookup
+// in a goroutine and waits up to timeout (defaultNodeMetadataTimeout if zero) for it to complete. A lookup that
+// finishes in time populates cache for future calls and, on success, is merged into a DeepCopy() of node exactly
+// like NodeLabelAugmenter's output, with node's own labels always winning. A lookup that doesn't finish before the
+// timeout falls back to the most recent cached result for this node if there is one, or to node.Labels alone
+// otherwise; its goroutine is left to finish on its own and, on success, update cache for the next call. A lookup
+// that returns an error in time falls back to node.Labels alone; the cache is not consulted.
+func nodeWithExtenderMetadata(node *v1.Node, provider NodeMetadataProvider, cache *nodeMetadataCache, timeout time.Duration) *v1.Node {
+	if node == nil || provider == nil {
+		return node
+	}
+	if timeout <= 0 {
+		timeout = defaultNodeMetadataTimeout
+	}
+
+	result := make(chan map[string]string, 1)
+	go func() {
+		metadata, err := provider.NodeMetadata(node.Name)
+		if err != nil {
+			result <- nil
+			return
+		}
+		cache.set(node.Name, metadata)
+		result <- metadata
+	}()
+
+	var metadata map[string]string
+	select {
+	case metadata = <-result:
+	case <-time.After(timeout):
+		metadata, _ = cache.get(node.Name)
+	}
+	if len(metadata) == 0 {
+		return node
+	}
+
+	needsPatch := false
+	for k := range metadata {
+		if _, exists := node.Labels[k]; !exists {
+			needsPatch = true
+			break
+		}
+	}
+	if !needsPatch {
+		return node
+	}
+
+	patched := node.DeepCopy()
+	if patched.Labels == nil {
+		patched.Labels = map[string]string{}
+	}
+	for k, v := range metadata {
+		if _, exists := patched.Labels[k]; !exists {
+			patched.Labels[k] = v
+		}
+	}
+	return patched
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeNodeMetadataProvider struct {
+	metadata map[string]string
+	delay    time.Duration
+	err      error
+}
+
+func (p *fakeNodeMetadataProvider) NodeMetadata(nodeName string) (map[string]string, error) {
+	if p.delay > 0 {
+		time.Sleep(p.delay)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.metadata, nil
+}
+
+func TestFilterMatchesOnExtenderProvidedMetadata(t *testing.T) {
+	pod := nodeSelectorPod("rack", "rack-7")
+	nodeInfo := nodeInfoWithLabels("node-1", map[string]string{"zone": "us-east-1"})
+
+	pl := &NodeAffinity{
+		selectorCache:        newSelectorCache(defaultSelectorCacheSize),
+		negativeCache:        newNegativeResultCache(defaultNegativeResultCacheSize),
+		nodeMetadataCache:    newNodeMetadataCache(),
+		NodeMetadataProvider: &fakeNodeMetadataProvider{metadata: map[string]string{"rack": "rack-7"}},
+		NodeMetadataTimeout:  time.Second,
+	}
+
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Fatalf("expected the node to match on extender-provided metadata, got: %v", status)
+	}
+}
+
+func TestFilterFallsBackToNodeLabelsOnMetadataTimeout(t *testing.T) {
+	pod := nodeSelectorPod("rack", "rack-7")
+	nodeInfo := nodeInfoWithLabels("node-1", map[string]string{"zone": "us-east-1"})
+
+	pl := &NodeAffinity{
+		selectorCache:        newSelectorCache(defaultSelectorCacheSize),
+		negativeCache:        newNegativeResultCache(defaultNegativeResultCacheSize),
+		nodeMetadataCache:    newNodeMetadataCache(),
+		NodeMetadataProvider: &fakeNodeMetadataProvider{metadata: map[string]string{"rack": "rack-7"}, delay: 50 * time.Millisecond},
+		NodeMetadataTimeout:  time.Millisecond,
+	}
+
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Fatal("expected the timed-out lookup to fall back to node.Labels alone and reject the node")
+	}
+}
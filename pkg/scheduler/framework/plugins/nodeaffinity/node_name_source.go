@@ -0,0 +1,40 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// resolveNodeName returns the value matchFields on the node-name key should compare against for node, given source.
+// An empty source, NodeAffinity's default, returns node.Name (metadata.name) unchanged. A non-empty source is
+// treated as an annotation key, so a cluster that references nodes by an external ID carried in an annotation
+// rather than metadata.name can have matchFields.metadata.name compare against that instead.
+func resolveNodeName(node *v1.Node, source string) string {
+	if source == "" {
+		return node.Name
+	}
+	return node.Annotations[source]
+}
+
+// requiredTermsMatchWithNodeNameSource reports whether node satisfies pod's required node selector/affinity terms,
+// exactly like MatchesLabels, except matchFields on the node-name key compares against resolveNodeName(node, source)
+// instead of node.Name.
+func requiredTermsMatchWithNodeNameSource(pod *v1.Pod, node *v1.Node, source string) (bool, error) {
+	return MatchesLabels(pod, labels.Set(node.Labels), resolveNodeName(node, source))
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func nodeNamePod(name string) *v1.Pod {
+	return &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{MatchFields: []v1.NodeSelectorRequirement{
+								{Key: schedulerapi.NodeFieldSelectorKeyNodeName, Operator: v1.NodeSelectorOpIn, Values: []string{name}},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestFilterMatchesFieldAgainstAnnotationSourcedName(t *testing.T) {
+	pod := nodeNamePod("external-id-1")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Annotations: map[string]string{"example.com/external-id": "external-id-1"}}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), NodeNameSource: "example.com/external-id"}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Errorf("expected a match against the annotation-sourced name, got status: %v", status)
+	}
+}
+
+func TestFilterMatchesFieldAgainstMetadataNameByDefault(t *testing.T) {
+	pod := nodeNamePod("node-1")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Annotations: map[string]string{"example.com/external-id": "external-id-1"}}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl, _ := New(nil, nil)
+	status := pl.(*NodeAffinity).Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Errorf("expected NodeNameSource unset to match against metadata.name, got status: %v", status)
+	}
+
+	pl2 := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), NodeNameSource: "example.com/external-id"}
+	status2 := pl2.Filter(context.Background(), nil, pod, nodeInfo)
+	if status2.IsSuccess() {
+		t.Errorf("expected NodeNameSource set to reject a match against metadata.name alone, got status: %v", status2)
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/workqueue"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	"k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// filterNodesParallelism matches the worker count the framework itself uses for RunFilterPlugins.
+const filterNodesParallelism = 16
+
+// FilterNodes runs Filter concurrently across nodeInfos using workqueue.ParallelizeUntil, the same helper the
+// scheduler framework uses internally, for callers driving nodeaffinity outside the standard scheduling loop.
+// Results are returned aligned by index with nodeInfos. Filter never mutates pl's configuration fields, and the
+// caches it does write to (negative results, node metadata) are guarded by their own locks, so it's safe to call
+// concurrently; PreFilter, if used at all, must have already
+// completed for pod before calling FilterNodes.
+func (pl *NodeAffinity) FilterNodes(ctx context.Context, pod *v1.Pod, nodeInfos []*nodeinfo.NodeInfo) []*framework.Status {
+	statuses := make([]*framework.Status, len(nodeInfos))
+	workqueue.ParallelizeUntil(ctx, filterNodesParallelism, len(nodeInfos), func(i int) {
+		statuses[i] = pl.Filter(ctx, nil, pod, nodeInfos[i])
+	})
+	return statuses
+}
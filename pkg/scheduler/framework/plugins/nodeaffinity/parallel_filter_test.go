@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestFilterNodesPreservesIndexAlignment(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1")
+
+	const numNodes = 200
+	nodeInfos := make([]*schedulernodeinfo.NodeInfo, numNodes)
+	for i := range nodeInfos {
+		zone := "us-west-1"
+		if i%2 == 0 {
+			zone = "us-east-1"
+		}
+		nodeInfo := schedulernodeinfo.NewNodeInfo()
+		nodeInfo.SetNode(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i), Labels: map[string]string{"zone": zone}}})
+		nodeInfos[i] = nodeInfo
+	}
+
+	pl, _ := New(nil, nil)
+	statuses := pl.(*NodeAffinity).FilterNodes(context.Background(), pod, nodeInfos)
+
+	if len(statuses) != numNodes {
+		t.Fatalf("expected %d statuses, got %d", numNodes, len(statuses))
+	}
+	for i, status := range statuses {
+		wantSuccess := i%2 == 0
+		if status.IsSuccess() != wantSuccess {
+			t.Errorf("node %d: expected success=%v, got status %v", i, wantSuccess, status)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+)
+
+// WeightedNodeSelectorTerm pairs a NodeSelectorTerm with a weight, mirroring v1.PreferredSchedulingTerm's shape for
+// a term that isn't sourced from a pod's own affinity, e.g. a plugin-configured PenaltyTerms entry.
+type WeightedNodeSelectorTerm struct {
+	// Weight is subtracted from a matching node's score by scorePenaltyTerms. Like a PreferredSchedulingTerm's
+	// weight, it's expected in the 1-100 range, but scorePenaltyTerms does not itself enforce that.
+	Weight int32
+	Term   v1.NodeSelectorTerm
+}
+
+// scorePenaltyTerms sums the Weight of every term in terms that matches node, for Score to subtract from a node's
+// otherwise-computed score. A term with no matchExpressions never matches, consistent with
+// v1helper.MatchNodeSelectorTerms.
+func scorePenaltyTerms(node *v1.Node, terms []WeightedNodeSelectorTerm) int64 {
+	var penalty int64
+	for _, weighted := range terms {
+		if len(weighted.Term.MatchExpressions) == 0 {
+			continue
+		}
+		selector, err := v1helper.NodeSelectorRequirementsAsSelector(weighted.Term.MatchExpressions)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(node.Labels)) {
+			penalty += int64(weighted.Weight)
+		}
+	}
+	return penalty
+}
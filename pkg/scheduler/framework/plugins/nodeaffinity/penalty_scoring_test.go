@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func weightedTerm(weight int32, key string, values ...string) WeightedNodeSelectorTerm {
+	return WeightedNodeSelectorTerm{
+		Weight: weight,
+		Term: v1.NodeSelectorTerm{
+			MatchExpressions: []v1.NodeSelectorRequirement{
+				{Key: key, Operator: v1.NodeSelectorOpIn, Values: values},
+			},
+		},
+	}
+}
+
+func TestScorePenalizesMatchingNode(t *testing.T) {
+	pod := preferredTermsPod(preferredTerm(100, "a", "1"))
+	tainted := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "tainted", Labels: map[string]string{"a": "1", "gpu": "old"}}}
+	clean := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "clean", Labels: map[string]string{"a": "1"}}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{tainted, clean})
+
+	pl, _ := New(nil, fh)
+	na := pl.(*NodeAffinity)
+	na.ScoringMode = ScoringModeCountUnmatchedPreferred
+	na.PenaltyTerms = []WeightedNodeSelectorTerm{weightedTerm(60, "gpu", "old")}
+
+	taintedScore, status := na.Score(context.Background(), nil, pod, "tainted")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error scoring tainted: %v", status)
+	}
+	cleanScore, status := na.Score(context.Background(), nil, pod, "clean")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error scoring clean: %v", status)
+	}
+
+	if cleanScore != 100 {
+		t.Errorf("expected the unpenalized node to score 100, got %d", cleanScore)
+	}
+	if taintedScore != 40 {
+		t.Errorf("expected the penalized node to score 100-60=40, got %d", taintedScore)
+	}
+}
+
+func TestScorePenaltyClampsAtMinNodeScore(t *testing.T) {
+	pod := preferredTermsPod(preferredTerm(10, "a", "1"))
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"a": "1", "gpu": "old"}}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{node})
+
+	pl, _ := New(nil, fh)
+	na := pl.(*NodeAffinity)
+	na.ScoringMode = ScoringModeCountUnmatchedPreferred
+	na.PenaltyTerms = []WeightedNodeSelectorTerm{weightedTerm(1000, "gpu", "old")}
+
+	score, status := na.Score(context.Background(), nil, pod, "node")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error: %v", status)
+	}
+	if score != framework.MinNodeScore {
+		t.Errorf("expected a heavy penalty to clamp at MinNodeScore (%d), got %d", framework.MinNodeScore, score)
+	}
+}
+
+func TestScorePenaltyIgnoredWhenNoTermsMatch(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"gpu": "new"}}}
+	if got := scorePenaltyTerms(node, []WeightedNodeSelectorTerm{weightedTerm(50, "gpu", "old")}); got != 0 {
+		t.Errorf("expected no penalty for a non-matching term, got %d", got)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+// ScoringMode selects how NodeAffinity.Score weighs a pod's PreferredDuringSchedulingIgnoredDuringExecution terms.
+type ScoringMode string
+
+const (
+	// ScoringModeDefault leaves Score's handling of preferred terms unchanged: this plugin does not itself score
+	// them (that's left to whatever weighted-sum scoring the rest of the scheduling stack applies). Zero value.
+	ScoringModeDefault ScoringMode = ""
+	// ScoringModeCountUnmatchedPreferred makes Score ignore per-term weight and instead score a node in proportion
+	// to how many of the pod's preferred terms it satisfies, favoring bin-packing onto nodes that violate the
+	// fewest preferred terms over nodes that happen to match one heavily-weighted term.
+	ScoringModeCountUnmatchedPreferred ScoringMode = "CountUnmatchedPreferred"
+	// ScoringModeCountMatchedRequirements makes Score ignore both per-term weight and term boundaries, instead
+	// scoring a node by the raw count of matchExpression requirements it satisfies across all of the pod's preferred
+	// terms, so a node satisfying three requirements from one term outscores a node satisfying only one requirement
+	// from another, even if the two terms carry equal weight.
+	ScoringModeCountMatchedRequirements ScoringMode = "CountMatchedRequirements"
+	// ScoringModeWeightedSumWithAttemptDecay scores a node by the standard weighted sum of the pod's matched
+	// preferred terms' Weight, except each term's effective weight is first shrunk by AttemptWeightDecayFactor for
+	// every scheduling attempt already recorded against the pod, so a pod that keeps failing to land on its
+	// preferred nodes gradually stops insisting on them.
+	ScoringModeWeightedSumWithAttemptDecay ScoringMode = "WeightedSumWithAttemptDecay"
+)
+
+// scoreCountUnmatchedPreferred scores node in proportion to the fraction of terms it matches, scaled to
+// framework.MaxNodeScore so it's comparable to other Score plugins' output. A pod with no preferred terms scores 0.
+func scoreCountUnmatchedPreferred(node *v1.Node, terms []v1.PreferredSchedulingTerm) int64 {
+	if len(terms) == 0 {
+		return 0
+	}
+
+	matched := 0
+	for _, term := range terms {
+		selector, err := v1helper.NodeSelectorRequirementsAsSelector(term.Preference.MatchExpressions)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(node.Labels)) {
+			matched++
+		}
+	}
+	return int64(matched) * framework.MaxNodeScore / int64(len(terms))
+}
+
+// scoreCountMatchedRequirements scores node in proportion to the fraction of the pod's preferred terms'
+// matchExpression requirements it satisfies, counted individually rather than per-term, scaled to
+// framework.MaxNodeScore. A pod with no preferred terms, or none carrying any requirements, scores 0.
+func scoreCountMatchedRequirements(node *v1.Node, terms []v1.PreferredSchedulingTerm) int64 {
+	total, matched := 0, 0
+	for _, term := range terms {
+		for _, requirement := range term.Preference.MatchExpressions {
+			total++
+			selector, err := v1helper.NodeSelectorRequirementsAsSelector([]v1.NodeSelectorRequirement{requirement})
+			if err != nil {
+				continue
+			}
+			if selector.Matches(labels.Set(node.Labels)) {
+				matched++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return int64(matched) * framework.MaxNodeScore / int64(total)
+}
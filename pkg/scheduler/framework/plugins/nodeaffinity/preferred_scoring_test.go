@@ -0,0 +1,145 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func preferredTermsPod(terms ...v1.PreferredSchedulingTerm) *v1.Pod {
+	return &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: terms,
+				},
+			},
+		},
+	}
+}
+
+func preferredTerm(weight int32, key string, values ...string) v1.PreferredSchedulingTerm {
+	return v1.PreferredSchedulingTerm{
+		Weight: weight,
+		Preference: v1.NodeSelectorTerm{
+			MatchExpressions: []v1.NodeSelectorRequirement{
+				{Key: key, Operator: v1.NodeSelectorOpIn, Values: values},
+			},
+		},
+	}
+}
+
+func TestScoreCountUnmatchedPreferredFavorsFewerViolations(t *testing.T) {
+	pod := preferredTermsPod(
+		preferredTerm(100, "a", "1"),
+		preferredTerm(1, "b", "1"),
+		preferredTerm(1, "c", "1"),
+		preferredTerm(1, "d", "1"),
+	)
+	// mostlyMatching matches 3/4 preferred terms; heavyWeightOnly matches only the single highest-weighted term.
+	mostlyMatching := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "mostly-matching", Labels: map[string]string{
+		"b": "1", "c": "1", "d": "1",
+	}}}
+	heavyWeightOnly := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "heavy-weight-only", Labels: map[string]string{
+		"a": "1",
+	}}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{mostlyMatching, heavyWeightOnly})
+
+	pl, _ := New(nil, fh)
+	na := pl.(*NodeAffinity)
+	na.ScoringMode = ScoringModeCountUnmatchedPreferred
+
+	mostlyMatchingScore, status := na.Score(context.Background(), nil, pod, "mostly-matching")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error scoring mostly-matching: %v", status)
+	}
+	heavyWeightOnlyScore, status := na.Score(context.Background(), nil, pod, "heavy-weight-only")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error scoring heavy-weight-only: %v", status)
+	}
+
+	if mostlyMatchingScore != 75 {
+		t.Errorf("expected the node matching 3/4 preferred terms to score 75, got %d", mostlyMatchingScore)
+	}
+	if heavyWeightOnlyScore != 25 {
+		t.Errorf("expected the node matching 1/4 preferred terms to score 25, got %d", heavyWeightOnlyScore)
+	}
+	if mostlyMatchingScore <= heavyWeightOnlyScore {
+		t.Errorf("expected the node violating fewer preferred terms to outscore the node matching one heavily-weighted term, got %d <= %d", mostlyMatchingScore, heavyWeightOnlyScore)
+	}
+}
+
+func TestScoreCountUnmatchedPreferredIgnoredWhenDisabled(t *testing.T) {
+	pod := preferredTermsPod(preferredTerm(100, "a", "1"))
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"a": "1"}}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{node})
+
+	pl, _ := New(nil, fh)
+	score, status := pl.(*NodeAffinity).Score(context.Background(), nil, pod, "node")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error: %v", status)
+	}
+	if score != 0 {
+		t.Errorf("expected a pod's real preferred terms to score 0 with the default ScoringMode, got %d", score)
+	}
+}
+
+func TestScoreCountUnmatchedPreferredMatchesViaAugmentedLabel(t *testing.T) {
+	pod := preferredTermsPod(preferredTerm(100, "capacity-tier", "high"))
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{node})
+
+	pl, _ := New(nil, fh)
+	na := pl.(*NodeAffinity)
+	na.ScoringMode = ScoringModeCountUnmatchedPreferred
+	na.NodeLabelAugmenter = func(n *v1.Node) map[string]string {
+		return map[string]string{"capacity-tier": "high"}
+	}
+
+	score, status := na.Score(context.Background(), nil, pod, "node")
+	if !status.IsSuccess() {
+		t.Fatalf("unexpected error: %v", status)
+	}
+	if score != 100 {
+		t.Errorf("expected a preferred term matching only via an augmented capacity-tier label to score 100, got %d", score)
+	}
+	if node.Labels != nil {
+		t.Error("expected the original node not to be mutated")
+	}
+}
+
+func TestScoreCountUnmatchedPreferredNoTerms(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	if got := scoreCountUnmatchedPreferred(node, nil); got != 0 {
+		t.Errorf("expected a pod with no preferred terms to score 0, got %d", got)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+// NormalizeScore implements framework.ScoreExtensions. It's only invoked when PreferSpecificPreferredTerms is
+// enabled (see ScoreExtensions); it groups scores by their exact value and, within any group of two or more nodes,
+// nudges every node down by one except the one(s) matching the pod's most specific (most matchExpressions) preferred
+// term, so a tie is broken toward specificity instead of being left to whatever arbitrary order the caller sorts
+// ties in. A group's nudge never reaches outside the group, so it can't reorder nodes that didn't already tie.
+func (pl *NodeAffinity) NormalizeScore(ctx context.Context, _ *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList) *framework.Status {
+	if !pl.PreferSpecificPreferredTerms {
+		return nil
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || len(affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution) == 0 {
+		return nil
+	}
+	terms := affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+
+	groups := map[int64][]int{}
+	for i, nodeScore := range scores {
+		groups[nodeScore.Score] = append(groups[nodeScore.Score], i)
+	}
+
+	for _, indices := range groups {
+		if len(indices) < 2 {
+			continue
+		}
+
+		specificity := make(map[int]int, len(indices))
+		best := 0
+		for _, i := range indices {
+			nodeInfo, exist := pl.handle.NodeInfoSnapshot().NodeInfoMap[scores[i].Name]
+			if !exist {
+				continue
+			}
+			s := matchedPreferredTermSpecificity(nodeInfo.Node(), terms)
+			specificity[i] = s
+			if s > best {
+				best = s
+			}
+		}
+		if best == 0 {
+			continue
+		}
+
+		for _, i := range indices {
+			if specificity[i] < best && scores[i].Score > framework.MinNodeScore {
+				scores[i].Score--
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchedPreferredTermSpecificity returns the largest number of matchExpressions among node's preferred terms that
+// match node's labels, or 0 if none match.
+func matchedPreferredTermSpecificity(node *v1.Node, terms []v1.PreferredSchedulingTerm) int {
+	best := 0
+	for _, term := range terms {
+		selector, err := v1helper.NodeSelectorRequirementsAsSelector(term.Preference.MatchExpressions)
+		if err != nil || !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		if n := len(term.Preference.MatchExpressions); n > best {
+			best = n
+		}
+	}
+	return best
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestNormalizeScoreBreaksTieBySpecificity(t *testing.T) {
+	pod := preferredTermsPod(
+		preferredTerm(50, "zone", "a"),
+		preferredTerm(50, "region", "x"),
+	)
+	// The second term is more specific: give it two more requirements the first doesn't have.
+	pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[1].Preference.MatchExpressions = append(
+		pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[1].Preference.MatchExpressions,
+		v1.NodeSelectorRequirement{Key: "rack", Operator: v1.NodeSelectorOpIn, Values: []string{"y"}},
+		v1.NodeSelectorRequirement{Key: "gpu", Operator: v1.NodeSelectorOpIn, Values: []string{"true"}},
+	)
+
+	// broad matches only the 1-requirement term; specific matches only the 3-requirement term. Both satisfy exactly
+	// one of the pod's two preferred terms, so scoreCountUnmatchedPreferred ties them before any tie-break.
+	broad := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "broad", Labels: map[string]string{"zone": "a"}}}
+	specific := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "specific", Labels: map[string]string{"region": "x", "rack": "y", "gpu": "true"}}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{broad, specific})
+
+	pl, _ := New(nil, fh)
+	na := pl.(*NodeAffinity)
+	na.ScoringMode = ScoringModeCountUnmatchedPreferred
+	na.PreferSpecificPreferredTerms = true
+
+	scores := framework.NodeScoreList{}
+	for _, name := range []string{"broad", "specific"} {
+		score, status := na.Score(context.Background(), nil, pod, name)
+		if !status.IsSuccess() {
+			t.Fatalf("unexpected error scoring %s: %v", name, status)
+		}
+		scores = append(scores, framework.NodeScore{Name: name, Score: score})
+	}
+
+	if scores[0].Score != scores[1].Score {
+		t.Fatalf("expected both nodes to tie before normalization, got %d and %d", scores[0].Score, scores[1].Score)
+	}
+
+	ext := na.ScoreExtensions()
+	if ext == nil {
+		t.Fatal("expected ScoreExtensions to return a non-nil ScoreExtensions when PreferSpecificPreferredTerms is set")
+	}
+	if status := ext.NormalizeScore(context.Background(), nil, pod, scores); !status.IsSuccess() {
+		t.Fatalf("unexpected error from NormalizeScore: %v", status)
+	}
+
+	byName := map[string]int64{}
+	for _, s := range scores {
+		byName[s.Name] = s.Score
+	}
+	if byName["specific"] <= byName["broad"] {
+		t.Errorf("expected the more specific match to outscore the broad one after tie-break, got specific=%d broad=%d",
+			byName["specific"], byName["broad"])
+	}
+}
+
+func TestScoreExtensionsNilByDefault(t *testing.T) {
+	na := &NodeAffinity{}
+	if ext := na.ScoreExtensions(); ext != nil {
+		t.Errorf("expected ScoreExtensions to be nil when PreferSpecificPreferredTerms is unset, got %v", ext)
+	}
+}
+
+func TestNormalizeScoreNoOpWithoutPreferredTerms(t *testing.T) {
+	na := &NodeAffinity{PreferSpecificPreferredTerms: true}
+	pod := &v1.Pod{}
+	scores := framework.NodeScoreList{{Name: "a", Score: 50}, {Name: "b", Score: 50}}
+	if status := na.NormalizeScore(context.Background(), nil, pod, scores); !status.IsSuccess() {
+		t.Fatalf("unexpected error: %v", status)
+	}
+	if scores[0].Score != 50 || scores[1].Score != 50 {
+		t.Errorf("expected scores to be unchanged without preferred terms, got %v", scores)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+)
+
+// providerIDFieldSelectorKey is the matchFields key resolved against node.Spec.ProviderID when
+// AllowProviderIDMatchField is enabled. It isn't one of the field selector keys the API server itself validates
+// matchFields against, so referencing it only ever does anything for a cluster that opted into this plugin arg.
+const providerIDFieldSelectorKey = "spec.providerID"
+
+// requiredTermsMatchWithProviderIDField reports whether node satisfies pod's legacy NodeSelector and required node
+// affinity terms, exactly like MatchesLabels, except a matchFields requirement on providerIDFieldSelectorKey is
+// additionally resolved against node.Spec.ProviderID. Every other matchFields key is resolved exactly as before
+// (only the node-name key has a value; any other key compares against the empty string, so it only matches a NotIn
+// requirement), so this stays purely additive to the existing matchFields behavior.
+func requiredTermsMatchWithProviderIDField(pod *v1.Pod, node *v1.Node) (bool, error) {
+	if len(pod.Spec.NodeSelector) > 0 {
+		if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+			return false, nil
+		}
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true, nil
+	}
+
+	nodeFields := fields.Set{
+		schedulerapi.NodeFieldSelectorKeyNodeName: node.Name,
+		providerIDFieldSelectorKey:                node.Spec.ProviderID,
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		matched, err := nodeSelectorTermMatchesLabels(term, labels.Set(node.Labels), nodeFields)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func providerIDPod(providerID string) *v1.Pod {
+	return &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{MatchFields: []v1.NodeSelectorRequirement{
+								{Key: providerIDFieldSelectorKey, Operator: v1.NodeSelectorOpIn, Values: []string{providerID}},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestFilterMatchesProviderIDFieldWhenEnabled(t *testing.T) {
+	pod := providerIDPod("aws:///us-east-1a/i-1234")
+	node := &v1.Node{Spec: v1.NodeSpec{ProviderID: "aws:///us-east-1a/i-1234"}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), AllowProviderIDMatchField: true}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Errorf("expected a match against a node's providerID, got status: %v", status)
+	}
+}
+
+func TestFilterFailsProviderIDFieldOnMismatch(t *testing.T) {
+	pod := providerIDPod("aws:///us-east-1a/i-1234")
+	node := &v1.Node{Spec: v1.NodeSpec{ProviderID: "aws:///us-east-1a/i-9999"}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), AllowProviderIDMatchField: true}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Error("expected a node with a different providerID to fail to match")
+	}
+}
+
+func TestFilterIgnoresProviderIDFieldByDefault(t *testing.T) {
+	pod := providerIDPod("aws:///us-east-1a/i-1234")
+	node := &v1.Node{Spec: v1.NodeSpec{ProviderID: "aws:///us-east-1a/i-1234"}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Error("expected spec.providerID matchFields to be left unresolved (and so never match) unless AllowProviderIDMatchField is set, even when the node's providerID equals the requested value")
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// matchesNodeSelectorAndAffinityRelaxed mirrors predicates.PodMatchesNodeSelectorAndAffinityTerms, except every
+// value comparison (pod.Spec.NodeSelector and matchExpressions) is done literally rather than through a
+// labels.Selector. A labels.Selector rejects any value over 63 chars as an invalid label value (see
+// labels.NewRequirement), which makes a match unwinnable against an augmented label whose value comes from a custom
+// resource rather than a real node label. It exists for the RelaxedValueValidation path; when wildcard is true (the
+// WildcardValues arg), it additionally treats an In/NotIn value ending in '*' as a prefix match, which a
+// labels.Selector can't express at all since '*' isn't a valid label-value character. transformers, keyed by label
+// key, is applied to both sides of every In/NotIn/equality comparison before it's made, for the ValueTransformers
+// arg; a key with no entry compares literally. The standard path is unaffected either way.
+func matchesNodeSelectorAndAffinityRelaxed(pod *v1.Pod, node *v1.Node, wildcard bool, transformers map[string]func(string) string) bool {
+	for k, v := range pod.Spec.NodeSelector {
+		if !valueMatchesRelaxed(node.Labels[k], v, wildcard, transformers[k]) {
+			return false
+		}
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return true
+	}
+	nodeAffinity := affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+
+	for _, term := range nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if matchesNodeSelectorTermRelaxed(node.Labels, term, wildcard, transformers) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNodeSelectorTermRelaxed reports whether nodeLabels satisfies every matchExpression in term. As in
+// v1helper.MatchNodeSelectorTerms, a term with no matchExpressions matches nothing; matchFields aren't evaluated
+// here since node fields aren't the long, custom-resource-sourced values RelaxedValueValidation exists for.
+func matchesNodeSelectorTermRelaxed(nodeLabels map[string]string, term v1.NodeSelectorTerm, wildcard bool, transformers map[string]func(string) string) bool {
+	if len(term.MatchExpressions) == 0 {
+		return false
+	}
+	for _, req := range term.MatchExpressions {
+		if !matchesNodeSelectorRequirementRelaxed(nodeLabels, req, wildcard, transformers[req.Key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesNodeSelectorRequirementRelaxed evaluates a single NodeSelectorRequirement against nodeLabels by literal
+// string comparison, with no restriction on value length. When wildcard is true, an In/NotIn value ending in '*' is
+// treated as a prefix match instead of requiring an exact match. transform, if non-nil, is applied to both the
+// node's value and each required value before comparison.
+func matchesNodeSelectorRequirementRelaxed(nodeLabels map[string]string, req v1.NodeSelectorRequirement, wildcard bool, transform func(string) string) bool {
+	value, exists := nodeLabels[req.Key]
+	switch req.Operator {
+	case v1.NodeSelectorOpIn:
+		if !exists {
+			return false
+		}
+		for _, v := range req.Values {
+			if valueMatchesRelaxed(value, v, wildcard, transform) {
+				return true
+			}
+		}
+		return false
+	case v1.NodeSelectorOpNotIn:
+		if !exists {
+			return true
+		}
+		for _, v := range req.Values {
+			if valueMatchesRelaxed(value, v, wildcard, transform) {
+				return false
+			}
+		}
+		return true
+	case v1.NodeSelectorOpExists:
+		return exists
+	case v1.NodeSelectorOpDoesNotExist:
+		return !exists
+	case v1.NodeSelectorOpGt, v1.NodeSelectorOpLt:
+		if !exists || len(req.Values) != 1 {
+			return false
+		}
+		nodeValue, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		reqValue, err := strconv.ParseInt(req.Values[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		if req.Operator == v1.NodeSelectorOpGt {
+			return nodeValue > reqValue
+		}
+		return nodeValue < reqValue
+	default:
+		return false
+	}
+}
+
+// valueMatchesRelaxed compares a node's label value against a required value, treating a trailing '*' on the
+// required value as a prefix wildcard when wildcard is true. If transform is non-nil, it's applied to both values
+// (the required value's trailing '*' stripped first, so transforming can't interfere with the wildcard marker)
+// before comparing; otherwise comparison is an exact string match.
+func valueMatchesRelaxed(nodeValue, requiredValue string, wildcard bool, transform func(string) string) bool {
+	if transform == nil {
+		transform = func(s string) string { return s }
+	}
+	if wildcard && strings.HasSuffix(requiredValue, "*") {
+		return strings.HasPrefix(transform(nodeValue), transform(strings.TrimSuffix(requiredValue, "*")))
+	}
+	return transform(nodeValue) == transform(requiredValue)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestFilterMatchesLongValueWhenRelaxed(t *testing.T) {
+	longValue := strings.Repeat("a", 100)
+	pod := nodeSelectorPod("custom.example.com/fingerprint", longValue)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{
+		selectorCache:          newSelectorCache(defaultSelectorCacheSize),
+		RelaxedValueValidation: true,
+		NodeLabelAugmenter: func(n *v1.Node) map[string]string {
+			return map[string]string{"custom.example.com/fingerprint": longValue}
+		},
+	}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Errorf("expected a 100-char value to match with RelaxedValueValidation on, got status: %v", status)
+	}
+}
+
+func TestFilterRejectsLongValueWhenNotRelaxed(t *testing.T) {
+	longValue := strings.Repeat("a", 100)
+	pod := nodeSelectorPod("custom.example.com/fingerprint", longValue)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{
+		selectorCache: newSelectorCache(defaultSelectorCacheSize),
+		NodeLabelAugmenter: func(n *v1.Node) map[string]string {
+			return map[string]string{"custom.example.com/fingerprint": longValue}
+		},
+	}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Error("expected a 100-char value to be unmatchable via the standard labels.Selector path")
+	}
+}
+
+func TestFilterMatchesWildcardValuePrefix(t *testing.T) {
+	pod := nodeSelectorPod("zone", "zone-a-*")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	node.Labels = map[string]string{"zone": "zone-a-rack-3"}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), WildcardValues: true}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Errorf("expected \"zone-a-*\" to match \"zone-a-rack-3\" with WildcardValues on, got status: %v", status)
+	}
+}
+
+func TestFilterWildcardValueDoesNotMatchOtherPrefix(t *testing.T) {
+	pod := nodeSelectorPod("zone", "zone-a-*")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	node.Labels = map[string]string{"zone": "zone-b-rack-1"}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), WildcardValues: true}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Error("expected \"zone-a-*\" not to match \"zone-b-rack-1\"")
+	}
+}
+
+func TestFilterWildcardSyntaxNotSpecialWhenDisabled(t *testing.T) {
+	pod := nodeSelectorPod("zone", "zone-a-*")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	node.Labels = map[string]string{"zone": "zone-a-rack-3"}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Error("expected the literal '*' value not to match anything when WildcardValues is off")
+	}
+}
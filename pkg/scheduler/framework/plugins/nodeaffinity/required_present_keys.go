@@ -0,0 +1,42 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// missingRequiredPresentKey returns the first key in required that node doesn't carry (regardless of value), and
+// true, or "", false if node has every key in required. An empty required list always returns false.
+func missingRequiredPresentKey(node *v1.Node, required []string) (string, bool) {
+	if len(required) == 0 || node == nil {
+		return "", false
+	}
+	for _, key := range required {
+		if _, ok := node.Labels[key]; !ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// requiredPresentKeyReason formats the Filter failure message for a node missing a cluster-required label key.
+func requiredPresentKeyReason(key string) string {
+	return fmt.Sprintf("node is missing required label key %q", key)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestFilterRejectsNodeMissingRequiredPresentKey(t *testing.T) {
+	pod := &v1.Pod{}
+	node := &v1.Node{}
+	node.Labels = map[string]string{"region": "us-east"}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), RequiredPresentKeys: []string{"zone"}}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+
+	if status.IsSuccess() {
+		t.Fatal("expected Filter to reject a node missing a required-present label key")
+	}
+	if status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected UnschedulableAndUnresolvable, got %v", status.Code())
+	}
+}
+
+func TestFilterAllowsNodeWithAllRequiredPresentKeys(t *testing.T) {
+	pod := &v1.Pod{}
+	node := &v1.Node{}
+	node.Labels = map[string]string{"zone": "us-east-1a", "region": "us-east"}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), RequiredPresentKeys: []string{"zone", "region"}}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+
+	if !status.IsSuccess() {
+		t.Errorf("expected Filter to allow a node carrying every required-present key, got status %v", status)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func requiredTermsPerPodSampleCount(t *testing.T) uint64 {
+	t.Helper()
+	m := &dto.Metric{}
+	histogram, ok := requiredTermsPerPod.ObserverMetric.(prometheus.Histogram)
+	if !ok {
+		t.Fatalf("expected an ObserverMetric backed by a prometheus.Histogram")
+	}
+	if err := histogram.Write(m); err != nil {
+		t.Fatalf("unexpected error reading metric: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestPreFilterRecordsRequiredTermsPerPod(t *testing.T) {
+	Register()
+	pl, _ := New(nil, nil)
+	na := pl.(*NodeAffinity)
+
+	before := requiredTermsPerPodSampleCount(t)
+
+	pod := nodeSelectorPod("zone", "a")
+	pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = append(
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms,
+		v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"b"}}}},
+	)
+	na.PreFilter(context.Background(), nil, pod)
+
+	if after := requiredTermsPerPodSampleCount(t); after != before+1 {
+		t.Errorf("expected one more observation, got %d before and %d after", before, after)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"container/list"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+)
+
+// defaultSelectorCacheSize bounds the number of compiled selector entries kept in memory. Re-submitted pods (e.g.
+// from Jobs) reuse the same affinity shape across attempts, so a small cache goes a long way without unbounded
+// growth.
+const defaultSelectorCacheSize = 512
+
+// compiledAffinity holds the compiled matchers for a pod's node affinity, keyed by pod identity so a changed pod
+// never serves stale matchers.
+type compiledAffinity struct {
+	requiredSelectors  []labels.Selector
+	preferredSelectors []preferredSelector
+}
+
+type preferredSelector struct {
+	selector labels.Selector
+	weight   int32
+}
+
+// selectorCacheKey identifies a pod's affinity shape. Including resourceVersion ensures a pod update (which may
+// change its affinity) is never served a stale cache entry for the same UID.
+type selectorCacheKey struct {
+	uid             types.UID
+	resourceVersion string
+}
+
+// selectorCache is a small bounded LRU cache of compiledAffinity, keyed by selectorCacheKey. It is safe for
+// concurrent use.
+type selectorCache struct {
+	lock     sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[selectorCacheKey]*list.Element
+}
+
+type selectorCacheEntry struct {
+	key   selectorCacheKey
+	value compiledAffinity
+}
+
+// newSelectorCache returns an empty selectorCache bounded to capacity entries.
+func newSelectorCache(capacity int) *selectorCache {
+	return &selectorCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[selectorCacheKey]*list.Element),
+	}
+}
+
+// get returns the cached compiledAffinity for key, if present, and marks it most-recently-used.
+func (c *selectorCache) get(key selectorCacheKey) (compiledAffinity, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return compiledAffinity{}, false
+	}
+	c.ll.MoveToFront(elem)
+	return elem.Value.(*selectorCacheEntry).value, true
+}
+
+// add inserts or updates the cache entry for key, evicting the least-recently-used entry if capacity is exceeded.
+func (c *selectorCache) add(key selectorCacheKey, value compiledAffinity) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*selectorCacheEntry).value = value
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	elem := c.ll.PushFront(&selectorCacheEntry{key: key, value: value})
+	c.items[key] = elem
+
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*selectorCacheEntry).key)
+	}
+}
+
+// len returns the number of entries currently cached. Intended for tests.
+func (c *selectorCache) len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.ll.Len()
+}
+
+// compileAffinity compiles the pod's required and preferred node affinity match expressions into reusable
+// selectors. Terms that fail to compile are skipped rather than failing PreFilter outright, since compilation is a
+// best-effort optimization and Filter/Score will still validate correctness through the uncached path.
+func compileAffinity(pod *v1.Pod) compiledAffinity {
+	var out compiledAffinity
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return out
+	}
+
+	if req := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; req != nil {
+		for _, term := range req.NodeSelectorTerms {
+			selector, err := v1helper.NodeSelectorRequirementsAsSelector(term.MatchExpressions)
+			if err != nil {
+				continue
+			}
+			out.requiredSelectors = append(out.requiredSelectors, selector)
+		}
+	}
+
+	for _, term := range affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		selector, err := v1helper.NodeSelectorRequirementsAsSelector(term.Preference.MatchExpressions)
+		if err != nil {
+			continue
+		}
+		out.preferredSelectors = append(out.preferredSelectors, preferredSelector{selector: selector, weight: term.Weight})
+	}
+
+	return out
+}
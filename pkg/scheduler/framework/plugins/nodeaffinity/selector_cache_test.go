@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func podWithUIDAndVersion(uid, resourceVersion string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:             types.UID(uid),
+			ResourceVersion: resourceVersion,
+		},
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{MatchExpressions: []v1.NodeSelectorRequirement{
+								{Key: "foo", Operator: v1.NodeSelectorOpIn, Values: []string{"bar"}},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSelectorCacheHitAndMissOnChangedResourceVersion(t *testing.T) {
+	pl, _ := New(nil, nil)
+	na := pl.(*NodeAffinity)
+
+	pod := podWithUIDAndVersion("pod-1", "1")
+	na.PreFilter(context.Background(), nil, pod)
+
+	if _, ok := na.selectorCache.get(selectorCacheKey{uid: pod.UID, resourceVersion: "1"}); !ok {
+		t.Fatal("expected a cache hit for the same pod UID+resourceVersion")
+	}
+
+	if _, ok := na.selectorCache.get(selectorCacheKey{uid: pod.UID, resourceVersion: "2"}); ok {
+		t.Fatal("expected a cache miss for a changed resourceVersion")
+	}
+}
+
+func TestSelectorCacheEviction(t *testing.T) {
+	c := newSelectorCache(2)
+	c.add(selectorCacheKey{uid: "pod-1", resourceVersion: "1"}, compiledAffinity{})
+	c.add(selectorCacheKey{uid: "pod-2", resourceVersion: "1"}, compiledAffinity{})
+	c.add(selectorCacheKey{uid: "pod-3", resourceVersion: "1"}, compiledAffinity{})
+
+	if c.len() != 2 {
+		t.Fatalf("expected cache to be bounded to 2 entries, got %d", c.len())
+	}
+	if _, ok := c.get(selectorCacheKey{uid: "pod-1", resourceVersion: "1"}); ok {
+		t.Error("expected the least-recently-used entry (pod-1) to have been evicted")
+	}
+	if _, ok := c.get(selectorCacheKey{uid: "pod-3", resourceVersion: "1"}); !ok {
+		t.Error("expected the most recently added entry (pod-3) to remain cached")
+	}
+}
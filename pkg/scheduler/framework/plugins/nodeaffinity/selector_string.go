@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// requiredSelectorString renders selectors, the compiled required node affinity selectors for a pod (one per
+// NodeSelectorTerm, ORed together), into a stable, human-readable form for logs and events, e.g.
+// "disktype && topology.kubernetes.io/zone in (a,b)" for a single term (requirements sorted by key), or
+// "zone in (a) || zone in (b)" when more than one term is present. A pod with no required terms renders as "<none>".
+func requiredSelectorString(selectors []labels.Selector) string {
+	if len(selectors) == 0 {
+		return "<none>"
+	}
+
+	terms := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		requirements, _ := selector.Requirements()
+		clauses := make([]string, 0, len(requirements))
+		for _, requirement := range requirements {
+			clauses = append(clauses, requirement.String())
+		}
+		terms = append(terms, strings.Join(clauses, " && "))
+	}
+	return strings.Join(terms, " || ")
+}
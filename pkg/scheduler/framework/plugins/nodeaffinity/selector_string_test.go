@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestRequiredSelectorStringRendersSingleTerm(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{
+								MatchExpressions: []v1.NodeSelectorRequirement{
+									{Key: "topology.kubernetes.io/zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a", "b"}},
+									{Key: "disktype", Operator: v1.NodeSelectorOpExists},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	compiled := compileAffinity(pod)
+	got := requiredSelectorString(compiled.requiredSelectors)
+	want := "disktype && topology.kubernetes.io/zone in (a,b)"
+	if got != want {
+		t.Errorf("requiredSelectorString() = %q, want %q", got, want)
+	}
+}
+
+func TestRequiredSelectorStringRendersMultipleTermsAndNoTerms(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}}}},
+							{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"b"}}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	compiled := compileAffinity(pod)
+	got := requiredSelectorString(compiled.requiredSelectors)
+	want := "zone in (a) || zone in (b)"
+	if got != want {
+		t.Errorf("requiredSelectorString() = %q, want %q", got, want)
+	}
+
+	if got := requiredSelectorString(nil); got != "<none>" {
+		t.Errorf("requiredSelectorString(nil) = %q, want %q", got, "<none>")
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// firstRequiredInTerm returns the key and values of the first In-operator matchExpression in the first
+// NodeSelectorTerm of pod's required node affinity, and true, or "", nil, false if there's none to check (no
+// required node affinity at all, a term list with other than exactly one term, or a term with no In
+// matchExpression). A pod with several terms is never checked since the term list is ORed: a node could satisfy
+// another term even if no node carries this one's values.
+func firstRequiredInTerm(pod *v1.Pod) (string, []string, bool) {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return "", nil, false
+	}
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 1 {
+		return "", nil, false
+	}
+	for _, expr := range terms[0].MatchExpressions {
+		if expr.Operator == v1.NodeSelectorOpIn && len(expr.Values) > 0 {
+			return expr.Key, expr.Values, true
+		}
+	}
+	return "", nil, false
+}
+
+// noNodeHasAnyValue reports whether no node in nodeInfoMap carries key with any value in values.
+func noNodeHasAnyValue(nodeInfoMap map[string]*schedulernodeinfo.NodeInfo, key string, values []string) bool {
+	wanted := make(map[string]bool, len(values))
+	for _, v := range values {
+		wanted[v] = true
+	}
+	for _, nodeInfo := range nodeInfoMap {
+		node := nodeInfo.Node()
+		if node == nil {
+			continue
+		}
+		if wanted[node.Labels[key]] {
+			return false
+		}
+	}
+	return true
+}
+
+// absentLabelReason formats the PreFilter failure message for a required In term no node in the snapshot can
+// satisfy.
+func absentLabelReason(key string, values []string) string {
+	return fmt.Sprintf("no node in the current snapshot has label %q with any of the values %v required by the pod's node affinity", key, values)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestPreFilterShortCircuitsAbsentRequiredLabel(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1a")
+	nodeA := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"zone": "us-west-1"}}}
+	nodeB := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "b", Labels: map[string]string{"zone": "us-west-2"}}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{nodeA, nodeB})
+
+	pl, _ := New(nil, fh)
+	na := pl.(*NodeAffinity)
+	na.ShortCircuitAbsentRequiredLabels = true
+
+	status := na.PreFilter(context.Background(), nil, pod)
+	if status.IsSuccess() {
+		t.Fatal("expected PreFilter to reject a pod requiring a label value absent from every node")
+	}
+	if status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected UnschedulableAndUnresolvable, got %v", status.Code())
+	}
+}
+
+func TestPreFilterDoesNotShortCircuitWhenLabelIsPresent(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1a")
+	nodeA := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "a", Labels: map[string]string{"zone": "us-west-1"}}}
+	nodeB := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "b", Labels: map[string]string{"zone": "us-east-1a"}}}
+
+	fh, _ := framework.NewFramework(nil, nil, nil)
+	snapshot := fh.NodeInfoSnapshot()
+	snapshot.NodeInfoMap = schedulernodeinfo.CreateNodeNameToInfoMap(nil, []*v1.Node{nodeA, nodeB})
+
+	pl, _ := New(nil, fh)
+	na := pl.(*NodeAffinity)
+	na.ShortCircuitAbsentRequiredLabels = true
+
+	if status := na.PreFilter(context.Background(), nil, pod); !status.IsSuccess() {
+		t.Errorf("expected PreFilter to succeed when at least one node has the required label, got: %v", status)
+	}
+}
+
+func TestPreFilterFallsBackWithoutASnapshot(t *testing.T) {
+	pod := nodeSelectorPod("zone", "us-east-1a")
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), ShortCircuitAbsentRequiredLabels: true}
+
+	if status := pl.PreFilter(context.Background(), nil, pod); !status.IsSuccess() {
+		t.Errorf("expected PreFilter to fall back to always succeeding without a handle, got: %v", status)
+	}
+}
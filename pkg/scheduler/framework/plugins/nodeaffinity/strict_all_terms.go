@@ -0,0 +1,58 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+)
+
+// requiredTermsMatchAllStrict reports whether node satisfies every one of pod's required NodeSelectorTerms, for
+// StrictAllTerms. It reuses nodeSelectorTermMatchesLabels, the same per-term matcher MatchesLabels is built on, so a
+// malformed matchExpression/matchField surfaces as an error here exactly as it would there, rather than being
+// swallowed as a non-match the way the API's OR semantics would let slide if some other term happened to match.
+func requiredTermsMatchAllStrict(pod *v1.Pod, node *v1.Node) (bool, error) {
+	if len(pod.Spec.NodeSelector) > 0 {
+		if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+			return false, nil
+		}
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return true, nil
+	}
+	nodeAffinity := affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true, nil
+	}
+
+	set := labels.Set(node.Labels)
+	nodeFields := fields.Set{schedulerapi.NodeFieldSelectorKeyNodeName: node.Name}
+	for _, term := range nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		matched, err := nodeSelectorTermMatchesLabels(term, set, nodeFields)
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
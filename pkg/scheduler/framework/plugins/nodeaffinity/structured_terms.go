@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// RequirementResult is the outcome of evaluating a single NodeSelectorRequirement (one matchExpression) against a
+// node's labels.
+type RequirementResult struct {
+	// Key is the label key the requirement applies to.
+	Key string
+	// Operator is the requirement's comparison operator, e.g. In, NotIn, Exists.
+	Operator v1.NodeSelectorOperator
+	// Matched reports whether the node satisfied this requirement on its own.
+	Matched bool
+}
+
+// TermResult is the outcome of evaluating a single, ORed RequiredDuringSchedulingIgnoredDuringExecution
+// NodeSelectorTerm against a node.
+type TermResult struct {
+	// TermIndex is this term's position in RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms.
+	TermIndex int
+	// Matched reports whether every requirement in the term matched, i.e. whether this term alone would let the pod
+	// match the node. As with the rest of node affinity matching, a term with no matchExpressions matches nothing.
+	Matched bool
+	// Requirements holds one RequirementResult per matchExpression in the term, in order.
+	Requirements []RequirementResult
+}
+
+// EvaluateTerms is a diagnostic hook, intended for controllers orchestrating placement that want machine-readable
+// per-term results rather than the plugin's overall pass/fail, that reports how each of pod's required
+// matchExpressions terms evaluated against node. It is a pure function layered over the same literal comparison
+// matchesNodeSelectorRequirementRelaxed uses, and like ExplainMatch and SuggestRelaxations never runs on the
+// Filter/Score hot path. Only matchExpressions are reported; matchFields and pod.Spec.NodeSelector aren't part of
+// the ORed term structure this hook exists to expose. Returns nil if pod has no required node affinity terms.
+func EvaluateTerms(pod *v1.Pod, node *v1.Node) []TermResult {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return nil
+	}
+	required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil {
+		return nil
+	}
+
+	var nodeLabels map[string]string
+	if node != nil {
+		nodeLabels = node.Labels
+	}
+
+	results := make([]TermResult, 0, len(required.NodeSelectorTerms))
+	for i, term := range required.NodeSelectorTerms {
+		termResult := TermResult{
+			TermIndex:    i,
+			Matched:      matchesNodeSelectorTermRelaxed(nodeLabels, term, false, nil),
+			Requirements: make([]RequirementResult, 0, len(term.MatchExpressions)),
+		}
+		for _, req := range term.MatchExpressions {
+			termResult.Requirements = append(termResult.Requirements, RequirementResult{
+				Key:      req.Key,
+				Operator: req.Operator,
+				Matched:  matchesNodeSelectorRequirementRelaxed(nodeLabels, req, false, nil),
+			})
+		}
+		results = append(results, termResult)
+	}
+	return results
+}
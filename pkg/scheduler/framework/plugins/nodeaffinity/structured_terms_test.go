@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEvaluateTermsWithOredTerms(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{MatchExpressions: []v1.NodeSelectorRequirement{
+								{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"us-west-1"}},
+							}},
+							{MatchExpressions: []v1.NodeSelectorRequirement{
+								{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"us-east-1"}},
+								{Key: "disktype", Operator: v1.NodeSelectorOpIn, Values: []string{"ssd"}},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"zone": "us-east-1", "disktype": "ssd"}}}
+
+	results := EvaluateTerms(pod, node)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 term results, got %d", len(results))
+	}
+
+	if results[0].TermIndex != 0 || results[0].Matched {
+		t.Errorf("expected term 0 (zone=us-west-1) not to match, got %+v", results[0])
+	}
+	if len(results[0].Requirements) != 1 || results[0].Requirements[0].Matched {
+		t.Errorf("expected term 0's sole requirement to be reported unmatched, got %+v", results[0].Requirements)
+	}
+
+	if results[1].TermIndex != 1 || !results[1].Matched {
+		t.Errorf("expected term 1 (zone=us-east-1,disktype=ssd) to match, got %+v", results[1])
+	}
+	if len(results[1].Requirements) != 2 {
+		t.Fatalf("expected 2 requirement results for term 1, got %d", len(results[1].Requirements))
+	}
+	for _, req := range results[1].Requirements {
+		if !req.Matched {
+			t.Errorf("expected requirement %q to match, got %+v", req.Key, req)
+		}
+	}
+}
+
+func TestEvaluateTermsNoRequiredAffinity(t *testing.T) {
+	pod := &v1.Pod{}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"zone": "us-east-1"}}}
+
+	if results := EvaluateTerms(pod, node); results != nil {
+		t.Errorf("expected nil results for a pod with no required node affinity, got %v", results)
+	}
+}
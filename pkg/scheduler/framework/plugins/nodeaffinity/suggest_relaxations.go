@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/algorithm/predicates"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// RelaxationSuggestion reports, for a single required requirement of a pod, how many of the candidate nodes would
+// match the pod's overall node affinity/selector if that one requirement were removed.
+type RelaxationSuggestion struct {
+	// Requirement describes the requirement that was dropped, e.g. "nodeSelector[zone]" or
+	// "matchExpression[disktype]".
+	Requirement string
+	// NodesMatchedIfDropped is the number of nodeInfos that would match with Requirement removed.
+	NodesMatchedIfDropped int
+}
+
+// SuggestRelaxations is a diagnostic hook, intended for tooling that explains "your pod matches 0 nodes, but
+// dropping requirement X would match 12", rather than for scheduling itself. It considers pod's NodeSelector
+// entries and, if RequiredDuringSchedulingIgnoredDuringExecution has exactly one term (the common case, and the
+// only one where "drop this requirement" has an unambiguous meaning), that term's match expressions and match
+// fields. A pod with more than one required term gets suggestions for its NodeSelector entries only, since removing a requirement from
+// one OR-branch while other branches are untouched doesn't straightforwardly relax the pod.
+func SuggestRelaxations(pod *v1.Pod, nodeInfos []*schedulernodeinfo.NodeInfo) []RelaxationSuggestion {
+	var suggestions []RelaxationSuggestion
+
+	for key := range pod.Spec.NodeSelector {
+		relaxed := pod.DeepCopy()
+		delete(relaxed.Spec.NodeSelector, key)
+		suggestions = append(suggestions, RelaxationSuggestion{
+			Requirement:           fmt.Sprintf("nodeSelector[%s]", key),
+			NodesMatchedIfDropped: countMatches(relaxed, nodeInfos),
+		})
+	}
+
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		if required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil && len(required.NodeSelectorTerms) == 1 {
+			term := required.NodeSelectorTerms[0]
+			for i := range term.MatchExpressions {
+				relaxed := pod.DeepCopy()
+				relaxedTerm := &relaxed.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0]
+				relaxedTerm.MatchExpressions = append(append([]v1.NodeSelectorRequirement{}, term.MatchExpressions[:i]...), term.MatchExpressions[i+1:]...)
+				suggestions = append(suggestions, RelaxationSuggestion{
+					Requirement:           fmt.Sprintf("matchExpression[%s]", term.MatchExpressions[i].Key),
+					NodesMatchedIfDropped: countMatches(relaxed, nodeInfos),
+				})
+			}
+			for i := range term.MatchFields {
+				relaxed := pod.DeepCopy()
+				relaxedTerm := &relaxed.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0]
+				relaxedTerm.MatchFields = append(append([]v1.NodeSelectorRequirement{}, term.MatchFields[:i]...), term.MatchFields[i+1:]...)
+				suggestions = append(suggestions, RelaxationSuggestion{
+					Requirement:           fmt.Sprintf("matchField[%s]", term.MatchFields[i].Key),
+					NodesMatchedIfDropped: countMatches(relaxed, nodeInfos),
+				})
+			}
+		}
+	}
+
+	return suggestions
+}
+
+// countMatches returns how many of nodeInfos match pod's node affinity/selector.
+func countMatches(pod *v1.Pod, nodeInfos []*schedulernodeinfo.NodeInfo) int {
+	count := 0
+	for _, nodeInfo := range nodeInfos {
+		node := nodeInfo.Node()
+		if node == nil {
+			continue
+		}
+		if predicates.PodMatchesNodeSelectorAndAffinityTerms(pod, node) {
+			count++
+		}
+	}
+	return count
+}
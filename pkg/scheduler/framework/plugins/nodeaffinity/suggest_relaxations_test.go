@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestSuggestRelaxationsFindsLimitingRequirement(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			NodeSelector: map[string]string{"zone": "us-east-1"},
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{{
+							MatchExpressions: []v1.NodeSelectorRequirement{
+								{Key: "disktype", Operator: v1.NodeSelectorOpIn, Values: []string{"ssd"}},
+								{Key: "rack", Operator: v1.NodeSelectorOpIn, Values: []string{"1"}},
+							},
+						}},
+					},
+				},
+			},
+		},
+	}
+
+	nodeInfos := []*schedulernodeinfo.NodeInfo{
+		nodeInfoWithLabels("node-1", map[string]string{"zone": "us-east-1", "disktype": "hdd", "rack": "1"}),
+		nodeInfoWithLabels("node-2", map[string]string{"zone": "us-east-1", "disktype": "hdd", "rack": "1"}),
+		nodeInfoWithLabels("node-3", map[string]string{"zone": "us-west-1", "disktype": "ssd", "rack": "1"}),
+	}
+
+	// Baseline: no node satisfies every requirement (node-1/2 fail disktype, node-3 fails zone).
+	if got := countMatches(pod, nodeInfos); got != 0 {
+		t.Fatalf("expected the unmodified pod to match 0 nodes, got %d", got)
+	}
+
+	suggestions := SuggestRelaxations(pod, nodeInfos)
+
+	got := map[string]int{}
+	for _, s := range suggestions {
+		got[s.Requirement] = s.NodesMatchedIfDropped
+	}
+
+	if got["matchExpression[disktype]"] != 2 {
+		t.Errorf("expected dropping the disktype requirement (the limiting factor) to match 2 nodes, got %d", got["matchExpression[disktype]"])
+	}
+	if got["matchExpression[rack]"] != 0 {
+		t.Errorf("expected dropping the rack requirement, which isn't limiting, to still match 0 nodes, got %d", got["matchExpression[rack]"])
+	}
+	if got["nodeSelector[zone]"] != 1 {
+		t.Errorf("expected dropping the zone requirement to match 1 node, got %d", got["nodeSelector[zone]"])
+	}
+}
+
+func TestSuggestRelaxationsMultipleTermsReturnsNoSuggestions(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}}}},
+							{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"b"}}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if suggestions := SuggestRelaxations(pod, nil); len(suggestions) != 0 {
+		t.Errorf("expected no suggestions for a pod with multiple required terms, got %v", suggestions)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+)
+
+// observeTermMatchLatency times evaluating each of pod's required NodeSelectorTerms against node individually,
+// recording an observation in termMatchLatency labeled by the term's index in
+// RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms. It reuses nodeSelectorTermMatchesLabels, the
+// same per-term matcher backing MatchesLabels and StrictAllTerms, so the timed evaluation is the same work Filter
+// would otherwise do. Its match result is discarded: this is purely for instrumentation, and doesn't influence
+// Filter's outcome, so it's safe regardless of which matching mode (relaxed/wildcard vs strict) Filter actually uses.
+func observeTermMatchLatency(pod *v1.Pod, node *v1.Node) {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return
+	}
+	nodeAffinity := affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return
+	}
+
+	set := labels.Set(node.Labels)
+	nodeFields := fields.Set{schedulerapi.NodeFieldSelectorKeyNodeName: node.Name}
+	for i, term := range nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		start := time.Now()
+		_, _ = nodeSelectorTermMatchesLabels(term, set, nodeFields)
+		termMatchLatency.WithLabelValues(strconv.Itoa(i)).Observe(time.Since(start).Seconds())
+	}
+}
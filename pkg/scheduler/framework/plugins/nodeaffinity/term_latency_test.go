@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func sampleCount(t *testing.T, termIndex string) uint64 {
+	t.Helper()
+	m := &dto.Metric{}
+	histogram, ok := termMatchLatency.WithLabelValues(termIndex).(prometheus.Histogram)
+	if !ok {
+		t.Fatalf("expected an Observer backed by a prometheus.Histogram")
+	}
+	if err := histogram.Write(m); err != nil {
+		t.Fatalf("unexpected error reading metric: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestMeasureTermLatencyRecordsObservationsWhenEnabled(t *testing.T) {
+	Register()
+	pod := nodeSelectorPod("zone", "a")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"zone": "a"}}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	before := sampleCount(t, "0")
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), MeasureTermLatency: true}
+	pl.Filter(context.Background(), nil, pod, nodeInfo)
+
+	if after := sampleCount(t, "0"); after != before+1 {
+		t.Errorf("expected one more observation for term 0, got %d before and %d after", before, after)
+	}
+}
+
+func TestMeasureTermLatencyDisabledByDefault(t *testing.T) {
+	Register()
+	pod := nodeSelectorPod("zone", "a")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"zone": "a"}}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	before := sampleCount(t, "0")
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	pl.Filter(context.Background(), nil, pod, nodeInfo)
+
+	if after := sampleCount(t, "0"); after != before {
+		t.Errorf("expected no new observations with MeasureTermLatency off, got %d before and %d after", before, after)
+	}
+}
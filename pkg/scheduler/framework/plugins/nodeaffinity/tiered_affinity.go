@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+)
+
+// TierAnnotationPrefix marks a required node affinity term (identified by its index in
+// RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) as belonging to a scheduling tier. Terms with no
+// matching annotation default to tier 0. This lets a pod express "strongly prefer term A, acceptable term B" without
+// mixing required and preferred affinity blocks: Filter is unaffected (required terms remain ORed as before), but a
+// node that only satisfies a lower (higher-numbered) tier is downgraded at the Score extension point relative to one
+// that satisfies tier 0.
+//
+// Example: "scheduling.k8s.io/node-affinity-tier-1: 2" puts the second required term (index 1) into tier 2.
+const TierAnnotationPrefix = "scheduling.k8s.io/node-affinity-tier-"
+
+// tierWeight is the Score contribution awarded for matching a term in the given tier. Lower tiers are worth more.
+const tierWeight = 10
+
+// ParseTieredRequiredTerms splits a pod's RequiredDuringSchedulingIgnoredDuringExecution terms into the hard tier
+// (tier 0, enforced by Filter as today) and softer tiers (contributed to Score) using TierAnnotationPrefix
+// annotations on the pod. A pod with no such annotations yields all of its terms in the hard tier, preserving
+// today's behavior.
+func ParseTieredRequiredTerms(pod *v1.Pod) (hardTerms []v1.NodeSelectorTerm, softTiers map[int][]v1.NodeSelectorTerm) {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return nil, nil
+	}
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+
+	softTiers = map[int][]v1.NodeSelectorTerm{}
+	for i, term := range terms {
+		tier := termTier(pod, i)
+		if tier == 0 {
+			hardTerms = append(hardTerms, term)
+			continue
+		}
+		softTiers[tier] = append(softTiers[tier], term)
+	}
+	if len(softTiers) == 0 {
+		softTiers = nil
+	}
+	return hardTerms, softTiers
+}
+
+// termTier returns the scheduling tier for the required term at index i, defaulting to 0 (hard) when unset or
+// unparsable.
+func termTier(pod *v1.Pod, i int) int {
+	raw, ok := pod.Annotations[TierAnnotationPrefix+strconv.Itoa(i)]
+	if !ok {
+		return 0
+	}
+	tier, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || tier < 0 {
+		return 0
+	}
+	return tier
+}
+
+// ScoreTieredRequiredTerms scores a node against the soft tiers produced by ParseTieredRequiredTerms. Each matching
+// term contributes tierWeight divided by its tier number, so lower (more important) tiers are worth more.
+func ScoreTieredRequiredTerms(node *v1.Node, softTiers map[int][]v1.NodeSelectorTerm) int64 {
+	var score int64
+	for tier, terms := range softTiers {
+		for _, term := range terms {
+			selector, err := v1helper.NodeSelectorRequirementsAsSelector(term.MatchExpressions)
+			if err != nil {
+				continue
+			}
+			if selector.Matches(labels.Set(node.Labels)) {
+				score += int64(tierWeight) / int64(tier)
+			}
+		}
+	}
+	return score
+}
+
+// HasTieredRequiredTerms reports whether the pod opted into tiered required terms via TierAnnotationPrefix.
+func HasTieredRequiredTerms(pod *v1.Pod) bool {
+	for k := range pod.Annotations {
+		if strings.HasPrefix(k, TierAnnotationPrefix) {
+			return true
+		}
+	}
+	return false
+}
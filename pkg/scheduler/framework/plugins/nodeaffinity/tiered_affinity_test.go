@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func twoTierPod() *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				TierAnnotationPrefix + "1": "2",
+			},
+		},
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{
+								// tier 0 (default, hard)
+								MatchExpressions: []v1.NodeSelectorRequirement{
+									{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}},
+								},
+							},
+							{
+								// tier 2, downgraded to Score
+								MatchExpressions: []v1.NodeSelectorRequirement{
+									{Key: "gpu", Operator: v1.NodeSelectorOpIn, Values: []string{"true"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestFilterTieredOnlySecondTierMatches(t *testing.T) {
+	pod := twoTierPod()
+
+	// the tier-0 term ("zone=a") does not match, but the tier-2 term ("gpu=true") does: since required terms remain
+	// ORed at the Filter extension point, the node is still schedulable.
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"gpu": "true"}}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl, _ := New(nil, nil)
+	status := pl.(*NodeAffinity).Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Fatalf("expected filter to succeed via the OR'd tier-2 term, got status: %v", status)
+	}
+}
+
+func TestScoreTieredSoftTierContributesPoints(t *testing.T) {
+	pod := twoTierPod()
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"gpu": "true"}}}
+
+	_, softTiers := ParseTieredRequiredTerms(pod)
+	if len(softTiers) != 1 {
+		t.Fatalf("expected 1 soft tier, got %d", len(softTiers))
+	}
+
+	score := ScoreTieredRequiredTerms(node, softTiers)
+	if score <= 0 {
+		t.Errorf("expected a positive score for the matching soft tier term, got %d", score)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// topologyLabelZone and topologyLabelRegion are the stable topology label keys. They aren't declared in this
+// snapshot's k8s.io/api/core/v1, which only carries the legacy failure-domain.beta.kubernetes.io labels, so we
+// spell them out here rather than reference a nonexistent constant.
+const (
+	topologyLabelZone   = "topology.kubernetes.io/zone"
+	topologyLabelRegion = "topology.kubernetes.io/region"
+)
+
+// DefaultTopologyLabelEquivalence is the built-in mapping between legacy failure-domain labels and their stable
+// topology.kubernetes.io replacements, in both directions, so a node carrying only one form still satisfies a
+// matchExpression written against the other. Callers wanting to add cloud-provider-specific pairs should copy this
+// map and add to it rather than mutate it in place.
+var DefaultTopologyLabelEquivalence = map[string]string{
+	v1.LabelZoneFailureDomain: topologyLabelZone,
+	topologyLabelZone:         v1.LabelZoneFailureDomain,
+	v1.LabelZoneRegion:        topologyLabelRegion,
+	topologyLabelRegion:       v1.LabelZoneRegion,
+}
+
+// nodeWithEquivalentTopologyLabels returns node unchanged if equivalence is empty, or a deep copy whose Labels
+// have been extended with the equivalent key for every label node already carries that appears in equivalence,
+// without overwriting a value the node already sets for that key. The returned Node is safe to mutate further by
+// the caller; it does not alias node.Labels.
+func nodeWithEquivalentTopologyLabels(node *v1.Node, equivalence map[string]string) *v1.Node {
+	if node == nil || len(equivalence) == 0 || len(node.Labels) == 0 {
+		return node
+	}
+
+	var additions map[string]string
+	for key, value := range node.Labels {
+		equivalentKey, ok := equivalence[key]
+		if !ok {
+			continue
+		}
+		if _, alreadySet := node.Labels[equivalentKey]; alreadySet {
+			continue
+		}
+		if additions == nil {
+			additions = map[string]string{}
+		}
+		additions[equivalentKey] = value
+	}
+	if len(additions) == 0 {
+		return node
+	}
+
+	merged := make(map[string]string, len(node.Labels)+len(additions))
+	for k, v := range node.Labels {
+		merged[k] = v
+	}
+	for k, v := range additions {
+		merged[k] = v
+	}
+
+	nodeCopy := node.DeepCopy()
+	nodeCopy.Labels = merged
+	return nodeCopy
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// inconsistentTopology reports the region/zone pair to blame if node carries both the region and zone topology
+// labels but consistency, keyed by region, doesn't list the node's zone among that region's zones. A node missing
+// either label, or whose region has no entry in consistency at all, is left alone: this only catches a zone that's
+// actively wrong for a region consistency does have an opinion about.
+func inconsistentTopology(node *v1.Node, consistency map[string][]string) (region, zone string, inconsistent bool) {
+	if len(consistency) == 0 || node == nil {
+		return "", "", false
+	}
+	region, hasRegion := node.Labels[topologyLabelRegion]
+	zone, hasZone := node.Labels[topologyLabelZone]
+	if !hasRegion || !hasZone {
+		return "", "", false
+	}
+	zones, ok := consistency[region]
+	if !ok {
+		return "", "", false
+	}
+	for _, z := range zones {
+		if z == zone {
+			return "", "", false
+		}
+	}
+	return region, zone, true
+}
+
+// topologyConsistencyReason formats the Status message for a node whose zone doesn't belong to its region.
+func topologyConsistencyReason(region, zone string) string {
+	return fmt.Sprintf("node's zone %q does not belong to its region %q per the configured topology", zone, region)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func nodeInfoWithRegionZone(name, region, zone string) *schedulernodeinfo.NodeInfo {
+	return nodeInfoWithLabels(name, map[string]string{
+		topologyLabelRegion: region,
+		topologyLabelZone:   zone,
+	})
+}
+
+func TestFilterRejectsNodeWithInconsistentRegionZone(t *testing.T) {
+	pod := &v1.Pod{}
+	nodeInfo := nodeInfoWithRegionZone("node-1", "us-east", "us-west-1a")
+
+	pl := &NodeAffinity{
+		selectorCache: newSelectorCache(defaultSelectorCacheSize),
+		TopologyConsistency: map[string][]string{
+			"us-east": {"us-east-1a", "us-east-1b"},
+		},
+	}
+
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Fatal("expected Filter to reject a node whose zone doesn't belong to its region")
+	}
+	if status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected UnschedulableAndUnresolvable, got %v", status.Code())
+	}
+}
+
+func TestFilterAllowsNodeWithConsistentRegionZone(t *testing.T) {
+	pod := &v1.Pod{}
+	nodeInfo := nodeInfoWithRegionZone("node-1", "us-east", "us-east-1a")
+
+	pl := &NodeAffinity{
+		selectorCache: newSelectorCache(defaultSelectorCacheSize),
+		TopologyConsistency: map[string][]string{
+			"us-east": {"us-east-1a", "us-east-1b"},
+		},
+	}
+
+	if status := pl.Filter(context.Background(), nil, pod, nodeInfo); !status.IsSuccess() {
+		t.Errorf("expected Filter to allow a node with a consistent region/zone, got: %v", status)
+	}
+}
+
+func TestFilterIgnoresRegionNotInTopologyConsistency(t *testing.T) {
+	pod := &v1.Pod{}
+	nodeInfo := nodeInfoWithRegionZone("node-1", "eu-west", "eu-west-1a")
+
+	pl := &NodeAffinity{
+		selectorCache: newSelectorCache(defaultSelectorCacheSize),
+		TopologyConsistency: map[string][]string{
+			"us-east": {"us-east-1a"},
+		},
+	}
+
+	if status := pl.Filter(context.Background(), nil, pod, nodeInfo); !status.IsSuccess() {
+		t.Errorf("expected Filter to allow a node whose region has no configured topology entry, got: %v", status)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func nodeSelectorPod(key string, values ...string) *v1.Pod {
+	return &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{MatchExpressions: []v1.NodeSelectorRequirement{
+								{Key: key, Operator: v1.NodeSelectorOpIn, Values: values},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestFilterMatchesEquivalentTopologyLabel(t *testing.T) {
+	pod := nodeSelectorPod(topologyLabelZone, "us-west-1a")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.LabelZoneFailureDomain: "us-west-1a"}}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl, _ := New(nil, nil)
+	status := pl.(*NodeAffinity).Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Errorf("expected a pod requiring the stable zone label to match a node with only the legacy label, got status: %v", status)
+	}
+}
+
+func TestFilterDisabledTopologyLabelEquivalence(t *testing.T) {
+	pod := nodeSelectorPod(topologyLabelZone, "us-west-1a")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.LabelZoneFailureDomain: "us-west-1a"}}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Error("expected filter to fail once TopologyLabelEquivalence is disabled")
+	}
+}
+
+func TestNodeWithEquivalentTopologyLabelsDoesNotOverwriteExisting(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+		v1.LabelZoneFailureDomain: "us-west-1a",
+		topologyLabelZone:         "explicit-value",
+	}}}
+
+	patched := nodeWithEquivalentTopologyLabels(node, DefaultTopologyLabelEquivalence)
+	if patched.Labels[topologyLabelZone] != "explicit-value" {
+		t.Errorf("expected the node's own topology label to be preserved, got %q", patched.Labels[topologyLabelZone])
+	}
+	if node.Labels[topologyLabelZone] != "explicit-value" {
+		t.Error("expected the original node not to be mutated")
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+// SpanRecorder is a minimal tracing hook that NodeAffinity uses to record span events while compiling and scoring
+// node affinity terms. It's satisfied by an adapter around whatever tracing library a scheduler binary wires up
+// (e.g. an OpenTelemetry span); this package takes no dependency on a specific tracer implementation. A nil
+// SpanRecorder, the default, makes tracing a no-op.
+type SpanRecorder interface {
+	RecordEvent(name string, attributes map[string]interface{})
+}
+
+// recordEvent is a nil-safe convenience wrapper so call sites don't need to guard every call with a nil check.
+func recordEvent(t SpanRecorder, name string, attributes map[string]interface{}) {
+	if t == nil {
+		return
+	}
+	t.RecordEvent(name, attributes)
+}
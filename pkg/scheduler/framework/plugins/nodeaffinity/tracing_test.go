@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// recordingTracer is a SpanRecorder that keeps every event it's given, for assertions in tests.
+type recordingTracer struct {
+	names []string
+}
+
+func (r *recordingTracer) RecordEvent(name string, _ map[string]interface{}) {
+	r.names = append(r.names, name)
+}
+
+func TestTracerRecordsPreFilterAndScoreEvents(t *testing.T) {
+	tracer := &recordingTracer{}
+	pl, _ := New(nil, nil)
+	na := pl.(*NodeAffinity)
+	na.Tracer = tracer
+
+	pod := &v1.Pod{}
+	na.PreFilter(context.Background(), nil, pod)
+	na.Score(context.Background(), nil, pod, "node-1")
+
+	want := []string{"nodeaffinity.PreFilter", "nodeaffinity.Score"}
+	if len(tracer.names) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, tracer.names)
+	}
+	for i, name := range want {
+		if tracer.names[i] != name {
+			t.Errorf("expected event %d to be %q, got %q", i, name, tracer.names[i])
+		}
+	}
+}
+
+func TestNilTracerIsNoOp(t *testing.T) {
+	pl, _ := New(nil, nil)
+	na := pl.(*NodeAffinity)
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			t.Fatalf("expected a nil Tracer to be a no-op, got panic: %v", rec)
+		}
+	}()
+	na.PreFilter(context.Background(), nil, &v1.Pod{})
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+)
+
+// looksTransientlyUnlabeled reports whether node has no labels at all and was created within gracePeriod, the
+// signature of a node whose kubelet hasn't yet reported its topology labels rather than a genuine mismatch.
+func looksTransientlyUnlabeled(node *v1.Node, gracePeriod time.Duration, now time.Time) bool {
+	return len(node.Labels) == 0 && now.Sub(node.CreationTimestamp.Time) < gracePeriod
+}
+
+// relaxIfTransientLabelGap downgrades a Filter failure from UnschedulableAndUnresolvable to Unschedulable, with a
+// retry-after hint appended to the message, when node looks like it just hasn't reported its labels yet
+// (looksTransientlyUnlabeled). A genuine label mismatch on an established node is left as UnschedulableAndUnresolvable
+// unchanged, since retrying wouldn't help. status is returned unchanged if gracePeriod is zero (the feature is off),
+// status is already successful, or node is nil.
+func relaxIfTransientLabelGap(status *framework.Status, node *v1.Node, gracePeriod time.Duration, now time.Time) *framework.Status {
+	if gracePeriod <= 0 || status.IsSuccess() || node == nil {
+		return status
+	}
+	if !looksTransientlyUnlabeled(node, gracePeriod, now) {
+		return status
+	}
+	retryAfter := gracePeriod - now.Sub(node.CreationTimestamp.Time)
+	return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("%s (node %q was registered recently and has no labels yet; retry after %s)", status.Message(), node.Name, retryAfter.Round(time.Second)))
+}
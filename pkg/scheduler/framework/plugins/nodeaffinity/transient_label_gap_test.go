@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework/v1alpha1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestFilterRelaxesFreshlyRegisteredUnlabeledNode(t *testing.T) {
+	now := time.Now()
+	pod := nodeSelectorPod("zone", "us-east-1")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "new-node", CreationTimestamp: metav1.NewTime(now.Add(-5 * time.Second))}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{
+		selectorCache:                newSelectorCache(defaultSelectorCacheSize),
+		TransientLabelGapGracePeriod: 30 * time.Second,
+		nowFn:                        func() time.Time { return now },
+	}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+
+	if status.Code() != framework.Unschedulable {
+		t.Errorf("expected a freshly-registered unlabeled node to be Unschedulable (resolvable), got %v", status.Code())
+	}
+	if status.IsSuccess() {
+		t.Error("expected the pod not to match the node yet")
+	}
+}
+
+func TestFilterDoesNotRelaxGenuineMismatch(t *testing.T) {
+	now := time.Now()
+	pod := nodeSelectorPod("zone", "us-east-1")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:              "established-node",
+		CreationTimestamp: metav1.NewTime(now.Add(-24 * time.Hour)),
+		Labels:            map[string]string{"zone": "us-west-1"},
+	}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{
+		selectorCache:                newSelectorCache(defaultSelectorCacheSize),
+		TransientLabelGapGracePeriod: 30 * time.Second,
+		nowFn:                        func() time.Time { return now },
+	}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+
+	if status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected a genuine mismatch on an established, labeled node to remain UnschedulableAndUnresolvable, got %v", status.Code())
+	}
+}
+
+func TestFilterIgnoresTransientLabelGapWhenDisabled(t *testing.T) {
+	now := time.Now()
+	pod := nodeSelectorPod("zone", "us-east-1")
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "new-node", CreationTimestamp: metav1.NewTime(now.Add(-5 * time.Second))}}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize), nowFn: func() time.Time { return now }}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+
+	if status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected default behavior (TransientLabelGapGracePeriod unset) to remain UnschedulableAndUnresolvable, got %v", status.Code())
+	}
+}
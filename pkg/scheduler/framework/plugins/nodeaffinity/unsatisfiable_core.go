@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	v1 "k8s.io/api/core/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+// Requirement is a single matchExpression pulled out of a pod's required node affinity term, returned by
+// FindUnsatisfiableCore.
+type Requirement struct {
+	// Key is the label key the requirement applies to.
+	Key string
+	// Operator is the requirement's comparison operator, e.g. In, NotIn, Exists.
+	Operator v1.NodeSelectorOperator
+	// Values are the requirement's comparison values, meaningful only for In/NotIn.
+	Values []string
+}
+
+// FindUnsatisfiableCore finds the smallest subset of pod's first required NodeSelectorTerm's matchExpressions that,
+// together, no node in nodeInfos satisfies -- diagnostic tooling, like SuggestRelaxations, for a hard-to-debug pod
+// that matches zero nodes: rather than reporting every one of its requirements, it narrows down to just the ones
+// that actually conflict. It uses a simple greedy minimization: starting from every matchExpression in the term, it
+// repeatedly tries dropping each remaining one and keeps the drop if what's left is still unsatisfiable by every
+// node. This isn't guaranteed to find the globally smallest unsatisfiable subset when several overlapping subsets
+// exist, but it's cheap and good enough for pointing a human at the requirements actually worth looking at. As in
+// SuggestRelaxations, a pod with anything other than exactly one required term returns nil, since dropping a
+// requirement from one OR-branch while other branches exist doesn't have an unambiguous meaning; so does a pod whose
+// full term is already satisfiable by some node, since there's nothing unsatisfiable to report.
+func FindUnsatisfiableCore(pod *v1.Pod, nodeInfos []*schedulernodeinfo.NodeInfo) []Requirement {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		return nil
+	}
+	required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) != 1 {
+		return nil
+	}
+
+	core := append([]v1.NodeSelectorRequirement{}, required.NodeSelectorTerms[0].MatchExpressions...)
+	if len(core) == 0 || matchExpressionsSatisfiedByAnyNode(pod, core, nodeInfos) {
+		return nil
+	}
+
+	for i := 0; i < len(core); {
+		candidate := append(append([]v1.NodeSelectorRequirement{}, core[:i]...), core[i+1:]...)
+		if len(candidate) > 0 && !matchExpressionsSatisfiedByAnyNode(pod, candidate, nodeInfos) {
+			core = candidate
+			continue
+		}
+		i++
+	}
+
+	requirements := make([]Requirement, 0, len(core))
+	for _, req := range core {
+		requirements = append(requirements, Requirement{Key: req.Key, Operator: req.Operator, Values: req.Values})
+	}
+	return requirements
+}
+
+// matchExpressionsSatisfiedByAnyNode reports whether some node in nodeInfos would match pod if its first required
+// term's matchExpressions were replaced with exprs.
+func matchExpressionsSatisfiedByAnyNode(pod *v1.Pod, exprs []v1.NodeSelectorRequirement, nodeInfos []*schedulernodeinfo.NodeInfo) bool {
+	candidate := pod.DeepCopy()
+	candidate.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions = exprs
+	return countMatches(candidate, nodeInfos) > 0
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func podWithRequiredTerm(reqs ...v1.NodeSelectorRequirement) *v1.Pod {
+	return &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{{MatchExpressions: reqs}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestFindUnsatisfiableCoreNarrowsThreeRequirementsToTwo(t *testing.T) {
+	pod := podWithRequiredTerm(
+		v1.NodeSelectorRequirement{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}},
+		v1.NodeSelectorRequirement{Key: "instance-type", Operator: v1.NodeSelectorOpIn, Values: []string{"big"}},
+		v1.NodeSelectorRequirement{Key: "gpu", Operator: v1.NodeSelectorOpIn, Values: []string{"true"}},
+	)
+
+	// No node has both instance-type=big and gpu=true together, so that pair alone is already unsatisfiable
+	// cluster-wide; zone is a red herring that doesn't need to be part of the minimal core.
+	nodeInfos := []*schedulernodeinfo.NodeInfo{
+		nodeInfoWithLabels("node-1", map[string]string{"zone": "a", "instance-type": "small", "gpu": "true"}),
+		nodeInfoWithLabels("node-2", map[string]string{"zone": "b", "instance-type": "big", "gpu": "false"}),
+	}
+
+	core := FindUnsatisfiableCore(pod, nodeInfos)
+	if len(core) != 2 {
+		t.Fatalf("expected a minimal unsatisfiable core of 2 requirements, got %d: %v", len(core), core)
+	}
+	keys := map[string]bool{}
+	for _, req := range core {
+		keys[req.Key] = true
+	}
+	if !keys["instance-type"] || !keys["gpu"] {
+		t.Errorf("expected the core to be {instance-type, gpu}, got %v", core)
+	}
+	if keys["zone"] {
+		t.Errorf("expected the non-conflicting zone requirement to be dropped from the core, got %v", core)
+	}
+}
+
+func TestFindUnsatisfiableCoreReturnsNilWhenSchedulable(t *testing.T) {
+	pod := podWithRequiredTerm(
+		v1.NodeSelectorRequirement{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}},
+		v1.NodeSelectorRequirement{Key: "instance-type", Operator: v1.NodeSelectorOpIn, Values: []string{"big"}},
+	)
+	nodeInfos := []*schedulernodeinfo.NodeInfo{
+		nodeInfoWithLabels("node-1", map[string]string{"zone": "a", "instance-type": "big"}),
+	}
+
+	if core := FindUnsatisfiableCore(pod, nodeInfos); core != nil {
+		t.Errorf("expected a schedulable pod to have no unsatisfiable core, got %v", core)
+	}
+}
+
+func TestFindUnsatisfiableCoreMultipleTermsReturnsNil(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{
+							{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}}}},
+							{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"b"}}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if core := FindUnsatisfiableCore(pod, nil); core != nil {
+		t.Errorf("expected a pod with multiple required terms to have no reported core, got %v", core)
+	}
+}
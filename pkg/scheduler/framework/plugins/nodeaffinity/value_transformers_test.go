@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeaffinity
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	schedulernodeinfo "k8s.io/kubernetes/pkg/scheduler/nodeinfo"
+)
+
+func TestFilterMatchesThroughValueTransformer(t *testing.T) {
+	pod := nodeSelectorPod("zone", "US-EAST-1A")
+	node := &v1.Node{}
+	node.Labels = map[string]string{"zone": "us-east-1a"}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{
+		selectorCache: newSelectorCache(defaultSelectorCacheSize),
+		ValueTransformers: map[string]func(string) string{
+			"zone": strings.ToLower,
+		},
+	}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if !status.IsSuccess() {
+		t.Errorf("expected the pod's uppercase zone to match the node's lowercase zone through the transformer, got status: %v", status)
+	}
+}
+
+func TestFilterFailsWithoutValueTransformer(t *testing.T) {
+	pod := nodeSelectorPod("zone", "US-EAST-1A")
+	node := &v1.Node{}
+	node.Labels = map[string]string{"zone": "us-east-1a"}
+	nodeInfo := schedulernodeinfo.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	pl := &NodeAffinity{selectorCache: newSelectorCache(defaultSelectorCacheSize)}
+	status := pl.Filter(context.Background(), nil, pod, nodeInfo)
+	if status.IsSuccess() {
+		t.Error("expected differently-cased values to fail to match without a transformer")
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+
+	"k8s.io/client-go/util/cert"
+	"k8s.io/klog"
+)
+
+// BundleDecoder converts raw CA bundle bytes into parsed certificates, so a client CA bundle isn't limited to PEM.
+type BundleDecoder func(bundle []byte) ([]*x509.Certificate, error)
+
+// PEMBundleDecoder is the BundleDecoder used when a controller's ClientCABundleDecoder is unset. Like
+// cert.ParseCertsPEM, a single corrupt certificate block fails the whole bundle.
+func PEMBundleDecoder(bundle []byte) ([]*x509.Certificate, error) {
+	return cert.ParseCertsPEM(bundle)
+}
+
+// LenientPEMBundleDecoder decodes each PEM certificate block independently: a block that fails to parse is logged
+// and skipped rather than discarding the whole bundle, the way PEMBundleDecoder does. Select it via a controller's
+// ClientCABundleDecoder when one bad cert shouldn't be able to take down every other trust anchor in the bundle.
+func LenientPEMBundleDecoder(bundle []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := bundle
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != cert.CertificateBlockType || len(block.Headers) != 0 {
+			continue
+		}
+
+		parsed, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			klog.Warningf("skipping invalid certificate block in client CA bundle: %v", err)
+			continue
+		}
+		certs = append(certs, parsed)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("data does not contain any valid certificates")
+	}
+	return certs, nil
+}
+
+// pkcs7SignedDataOID identifies the degenerate, certificates-only SignedData ContentInfo that a PKCS#7 (.p7b)
+// "certs-only" bundle carries, as produced by e.g. `openssl crl2pkcs7 -nocrl -certfile ...`.
+var pkcs7SignedDataOID = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue `asn1:"set"`
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0,implicit"`
+	Crls             asn1.RawValue `asn1:"optional,tag:1,implicit"`
+	SignerInfos      asn1.RawValue `asn1:"set"`
+}
+
+// PKCS7BundleDecoder is a BundleDecoder for a DER-encoded PKCS#7 SignedData bundle, the degenerate
+// certificates-only form some enterprise CAs distribute (.p7b) instead of PEM.
+func PKCS7BundleDecoder(bundle []byte) ([]*x509.Certificate, error) {
+	var outer pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(bundle, &outer); err != nil {
+		return nil, fmt.Errorf("unable to parse PKCS#7 ContentInfo: %v", err)
+	}
+	if !outer.ContentType.Equal(pkcs7SignedDataOID) {
+		return nil, fmt.Errorf("unsupported PKCS#7 content type %v, expected SignedData", outer.ContentType)
+	}
+
+	var signedData pkcs7SignedData
+	if _, err := asn1.Unmarshal(outer.Content.Bytes, &signedData); err != nil {
+		return nil, fmt.Errorf("unable to parse PKCS#7 SignedData: %v", err)
+	}
+
+	var certs []*x509.Certificate
+	rest := signedData.Certificates.Bytes
+	for len(rest) > 0 {
+		var raw asn1.RawValue
+		var err error
+		rest, err = asn1.Unmarshal(rest, &raw)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse a certificate in the PKCS#7 bundle: %v", err)
+		}
+		parsed, err := x509.ParseCertificate(raw.FullBytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse a certificate in the PKCS#7 bundle: %v", err)
+		}
+		certs = append(certs, parsed)
+	}
+	return certs, nil
+}
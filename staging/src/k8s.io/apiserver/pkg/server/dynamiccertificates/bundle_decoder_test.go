@@ -0,0 +1,175 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"testing"
+
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// newTestCACertDER returns a freshly minted, parsed self-signed CA certificate for the given common name; its DER
+// encoding is in Raw.
+func newTestCACertDER(t *testing.T, commonName string) *x509.Certificate {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: commonName, Organization: []string{"test"}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certificate
+}
+
+// newTestPKCS7Bundle DER-encodes certs as a degenerate, certificates-only PKCS#7 SignedData bundle, matching what
+// `openssl crl2pkcs7 -nocrl -certfile ...` produces.
+func newTestPKCS7Bundle(t *testing.T, certs ...*x509.Certificate) []byte {
+	t.Helper()
+
+	certVals := make([]asn1.RawValue, len(certs))
+	for i, c := range certs {
+		certVals[i] = asn1.RawValue{FullBytes: c.Raw}
+	}
+	certsBytes, err := asn1.MarshalWithParams(certVals, "tag:0,implicit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataContentInfo, err := asn1.Marshal(struct{ ContentType asn1.ObjectIdentifier }{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptySet, err := asn1.MarshalWithParams([]asn1.RawValue{}, "set")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sd := pkcs7SignedData{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{FullBytes: emptySet},
+		ContentInfo:      asn1.RawValue{FullBytes: dataContentInfo},
+		Certificates:     asn1.RawValue{FullBytes: certsBytes},
+		SignerInfos:      asn1.RawValue{FullBytes: emptySet},
+	}
+	sdBytes, err := asn1.Marshal(sd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := asn1.Marshal(pkcs7ContentInfo{
+		ContentType: pkcs7SignedDataOID,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sdBytes},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestPKCS7BundleDecoder(t *testing.T) {
+	certA := newTestCACertDER(t, "bundle-a")
+	certB := newTestCACertDER(t, "bundle-b")
+	bundle := newTestPKCS7Bundle(t, certA, certB)
+
+	certs, err := PKCS7BundleDecoder(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) != 2 {
+		t.Fatalf("expected 2 certs, got %d", len(certs))
+	}
+	if certs[0].Subject.CommonName != "bundle-a" || certs[1].Subject.CommonName != "bundle-b" {
+		t.Errorf("expected certs in bundle order, got %q and %q", certs[0].Subject.CommonName, certs[1].Subject.CommonName)
+	}
+}
+
+func TestPKCS7BundleDecoderRejectsNonPKCS7(t *testing.T) {
+	if _, err := PKCS7BundleDecoder(newTestCACertPEM(t, "not-pkcs7")); err == nil {
+		t.Error("expected an error decoding PEM content as PKCS#7")
+	}
+}
+
+func TestSyncCertsWithPKCS7ClientCABundleDecoder(t *testing.T) {
+	certA := newTestCACertDER(t, "bundle-a")
+	bundle := newTestPKCS7Bundle(t, certA)
+
+	c := &DynamicServingCertificateController{
+		clientCA:              NewStaticCAContent("test-ca", bundle),
+		ClientCABundleDecoder: PKCS7BundleDecoder,
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(c.CurrentClientCAPool().Subjects()); got != 1 {
+		t.Errorf("expected the PKCS#7 bundle's cert to be trusted, got %d subjects", got)
+	}
+}
+
+func TestLenientPEMBundleDecoderSkipsCorruptBlock(t *testing.T) {
+	good := newTestCACertPEM(t, "good")
+	corrupt := []byte("-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydA==\n-----END CERTIFICATE-----\n")
+	bundle := append(append([]byte{}, good...), corrupt...)
+
+	certs, err := LenientPEMBundleDecoder(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) != 1 || certs[0].Subject.CommonName != "good" {
+		t.Errorf("expected only the valid cert to survive, got %+v", certs)
+	}
+}
+
+func TestLenientPEMBundleDecoderAllCorrupt(t *testing.T) {
+	corrupt := []byte("-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydA==\n-----END CERTIFICATE-----\n")
+	if _, err := LenientPEMBundleDecoder(corrupt); err == nil {
+		t.Error("expected an error when no certificate in the bundle parses")
+	}
+}
+
+func TestSyncCertsWithLenientClientCABundleDecoder(t *testing.T) {
+	good := newTestCACertPEM(t, "good")
+	corrupt := []byte("-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydA==\n-----END CERTIFICATE-----\n")
+	bundle := append(append([]byte{}, good...), corrupt...)
+
+	c := &DynamicServingCertificateController{
+		clientCA:              NewStaticCAContent("test-ca", bundle),
+		ClientCABundleDecoder: LenientPEMBundleDecoder,
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(c.CurrentClientCAPool().Subjects()); got != 1 {
+		t.Errorf("expected the valid cert to be trusted despite the corrupt block, got %d subjects", got)
+	}
+}
+
+func TestSyncCertsDefaultDecoderRejectsPKCS7(t *testing.T) {
+	certA := newTestCACertDER(t, "bundle-a")
+	bundle := newTestPKCS7Bundle(t, certA)
+
+	c := &DynamicServingCertificateController{
+		clientCA: NewStaticCAContent("test-ca", bundle),
+	}
+	if err := c.RunOnce(); err == nil {
+		t.Error("expected the default PEM decoder to reject a PKCS#7 bundle")
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+)
+
+// CertPolicy lets a deployment enforce constraints (minimum key size, allowed signature algorithms, ...) on every
+// certificate syncCerts loads, beyond what crypto/tls itself checks. Set DynamicServingCertificateController's
+// CertPolicy field to plug one in; nil, the default, leaves every certificate accepted.
+type CertPolicy interface {
+	// Validate returns an error describing why cert violates the policy, or nil if it's compliant.
+	Validate(cert *x509.Certificate) error
+}
+
+// permissiveCertPolicy accepts every certificate.
+type permissiveCertPolicy struct{}
+
+func (permissiveCertPolicy) Validate(*x509.Certificate) error { return nil }
+
+// PermissiveCertPolicy is the default, no-op CertPolicy: every certificate passes. It's equivalent to leaving
+// CertPolicy unset, and exists so a caller composing several policies can name it explicitly.
+var PermissiveCertPolicy CertPolicy = permissiveCertPolicy{}
+
+// MinKeySizePolicy rejects an RSA certificate whose modulus, or an ECDSA certificate whose curve, is narrower than
+// MinBits. A certificate using any other key algorithm is accepted, since this policy has no basis for judging it.
+type MinKeySizePolicy struct {
+	MinBits int
+}
+
+// Validate implements CertPolicy.
+func (p MinKeySizePolicy) Validate(cert *x509.Certificate) error {
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if bits := pub.N.BitLen(); bits < p.MinBits {
+			return fmt.Errorf("certificate %q has a %d-bit RSA key, want at least %d bits", cert.Subject, bits, p.MinBits)
+		}
+	case *ecdsa.PublicKey:
+		if bits := pub.Curve.Params().BitSize; bits < p.MinBits {
+			return fmt.Errorf("certificate %q has a %d-bit ECDSA key, want at least %d bits", cert.Subject, bits, p.MinBits)
+		}
+	}
+	return nil
+}
+
+// AllowedSignatureAlgorithmsPolicy rejects a certificate signed with an algorithm not in Allowed.
+type AllowedSignatureAlgorithmsPolicy struct {
+	Allowed []x509.SignatureAlgorithm
+}
+
+// Validate implements CertPolicy.
+func (p AllowedSignatureAlgorithmsPolicy) Validate(cert *x509.Certificate) error {
+	for _, alg := range p.Allowed {
+		if cert.SignatureAlgorithm == alg {
+			return nil
+		}
+	}
+	return fmt.Errorf("certificate %q uses signature algorithm %v, which isn't in the allowed set %v", cert.Subject, cert.SignatureAlgorithm, p.Allowed)
+}
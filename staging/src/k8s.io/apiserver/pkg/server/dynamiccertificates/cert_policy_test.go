@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// newTestCACertPEMWithKeySize is like newTestCACertPEM but with a caller-chosen RSA key size, so a test can mint a
+// deliberately weak certificate.
+func newTestCACertPEMWithKeySize(t *testing.T, commonName string, bits int) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: commonName, Organization: []string{"test"}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})
+}
+
+func TestSyncCertsRejectsWeakClientCAUnderMinKeySizePolicy(t *testing.T) {
+	weakCA := newTestCACertPEMWithKeySize(t, "weak-ca", 1024)
+
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:     tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:          NewStaticCAContent("test-ca", weakCA),
+		CertPolicy:        MinKeySizePolicy{MinBits: 2048},
+		RequireCertPolicy: true,
+	}
+
+	err := c.RunOnce()
+	if err == nil {
+		t.Fatal("expected RunOnce to fail for a client CA violating MinKeySizePolicy")
+	}
+	if !strings.Contains(err.Error(), "1024-bit RSA key") {
+		t.Errorf("expected the error to name the offending key size, got: %v", err)
+	}
+}
+
+func TestSyncCertsWarnsButLoadsWeakClientCAWhenNotRequired(t *testing.T) {
+	weakCA := newTestCACertPEMWithKeySize(t, "weak-ca", 1024)
+
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:      NewStaticCAContent("test-ca", weakCA),
+		CertPolicy:    MinKeySizePolicy{MinBits: 2048},
+	}
+
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("expected RunOnce to succeed with RequireCertPolicy unset, got: %v", err)
+	}
+	if len(c.CurrentClientCAPool().Subjects()) != 1 {
+		t.Error("expected the weak client CA to still be loaded, just with a warning")
+	}
+}
+
+func TestMinKeySizePolicyAcceptsCompliantCert(t *testing.T) {
+	strongCA := newTestCACertPEM(t, "strong-ca")
+
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:     tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:          NewStaticCAContent("test-ca", strongCA),
+		CertPolicy:        MinKeySizePolicy{MinBits: 2048},
+		RequireCertPolicy: true,
+	}
+
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("expected RunOnce to succeed for a compliant client CA, got: %v", err)
+	}
+}
+
+func TestAllowedSignatureAlgorithmsPolicyRejectsDisallowedAlgorithm(t *testing.T) {
+	strongCA := newTestCACertPEM(t, "strong-ca")
+	cert, err := certutil.ParseCertsPEM(strongCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := AllowedSignatureAlgorithmsPolicy{Allowed: nil}
+	if err := policy.Validate(cert[0]); err == nil {
+		t.Fatal("expected a certificate to be rejected against an empty allowed list")
+	}
+}
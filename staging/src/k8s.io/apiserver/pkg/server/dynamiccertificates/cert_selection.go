@@ -0,0 +1,39 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+)
+
+// selectServingCertificate picks the single certificate from certs that clientHello supports, so a controller
+// configured with more than one serving certificate (e.g. a ServingCertProvider supplying both an ECDSA and an RSA
+// certificate, to accommodate clients that vary in which they can validate) presents each client one it can
+// actually use, instead of always the first regardless of fit. certs is returned unchanged if it holds fewer than
+// two certificates, or if none of them supports clientHello, so crypto/tls's own handshake code reports the
+// mismatch exactly as it would have without this selection.
+func selectServingCertificate(clientHello *tls.ClientHelloInfo, certs []tls.Certificate) []tls.Certificate {
+	if len(certs) < 2 {
+		return certs
+	}
+	for i := range certs {
+		if clientHello.SupportsCertificate(&certs[i]) == nil {
+			return certs[i : i+1]
+		}
+	}
+	return certs
+}
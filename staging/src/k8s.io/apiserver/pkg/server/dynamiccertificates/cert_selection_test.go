@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func newTestECDSAServingCert(t *testing.T, dnsNames ...string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "serving-ecdsa"},
+		DNSNames:              dnsNames,
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestSelectServingCertificatePrefersECDSA(t *testing.T) {
+	ecdsaCert := newTestECDSAServingCert(t, "example.com")
+	rsaCert := newTestServingCert(t, "example.com")
+	certs := []tls.Certificate{rsaCert, ecdsaCert}
+
+	clientHello := &tls.ClientHelloInfo{
+		SignatureSchemes:  []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+		SupportedVersions: []uint16{tls.VersionTLS13},
+	}
+
+	selected := selectServingCertificate(clientHello, certs)
+	if len(selected) != 1 {
+		t.Fatalf("expected exactly one certificate to be selected, got %d", len(selected))
+	}
+	if _, ok := selected[0].PrivateKey.(*ecdsa.PrivateKey); !ok {
+		t.Errorf("expected the ECDSA-only client to be served the ECDSA certificate, got %T", selected[0].PrivateKey)
+	}
+}
+
+func TestSelectServingCertificatePrefersRSA(t *testing.T) {
+	ecdsaCert := newTestECDSAServingCert(t, "example.com")
+	rsaCert := newTestServingCert(t, "example.com")
+	certs := []tls.Certificate{ecdsaCert, rsaCert}
+
+	clientHello := &tls.ClientHelloInfo{
+		SignatureSchemes:  []tls.SignatureScheme{tls.PSSWithSHA256},
+		SupportedVersions: []uint16{tls.VersionTLS13},
+	}
+
+	selected := selectServingCertificate(clientHello, certs)
+	if len(selected) != 1 {
+		t.Fatalf("expected exactly one certificate to be selected, got %d", len(selected))
+	}
+	if _, ok := selected[0].PrivateKey.(*ecdsa.PrivateKey); ok {
+		t.Errorf("expected the RSA-only client to be served the RSA certificate, got %T", selected[0].PrivateKey)
+	}
+}
+
+func TestSelectServingCertificateUnchangedForFewerThanTwo(t *testing.T) {
+	certs := []tls.Certificate{newTestServingCert(t, "example.com")}
+	if got := selectServingCertificate(&tls.ClientHelloInfo{}, certs); len(got) != 1 {
+		t.Errorf("expected a single-certificate list to pass through unchanged, got %d", len(got))
+	}
+}
+
+func TestGetConfigForClientSelectsMatchingCertificate(t *testing.T) {
+	ecdsaCert := newTestECDSAServingCert(t, "example.com")
+	rsaCert := newTestServingCert(t, "example.com")
+
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{Certificates: []tls.Certificate{rsaCert, ecdsaCert}},
+		clientCA:      NewStaticCAContent("test-ca", newTestCACertPEM(t, "ca")),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	served, err := c.GetConfigForClient(&tls.ClientHelloInfo{
+		SignatureSchemes:  []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+		SupportedVersions: []uint16{tls.VersionTLS13},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(served.Certificates) != 1 {
+		t.Fatalf("expected exactly one certificate in the served config, got %d", len(served.Certificates))
+	}
+	if _, ok := served.Certificates[0].PrivateKey.(*ecdsa.PrivateKey); !ok {
+		t.Errorf("expected an ECDSA-preferring client to be served the ECDSA certificate, got %T", served.Certificates[0].PrivateKey)
+	}
+}
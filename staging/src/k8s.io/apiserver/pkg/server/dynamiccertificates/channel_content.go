@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"sync/atomic"
+)
+
+// Listener is enqueued every time a channelCAContent receives a new bundle, so that it resyncs against the latest
+// content. *DynamicServingCertificateController satisfies this.
+type Listener interface {
+	Enqueue()
+}
+
+// channelCAContent is a CAContentProvider driven by a caller-owned channel instead of polling a file or informer.
+type channelCAContent struct {
+	name      string
+	caBundle  atomic.Value // holds []byte
+	listeners []Listener
+}
+
+// NewChannelCAContentProvider returns a CAContentProvider that reads new ca bundles off bundles as they're sent,
+// storing each one for CurrentCABundleContent and enqueuing every listener so it resyncs. It runs until bundles is
+// closed. This lets a caller with its own change stream (rather than fsnotify or an informer) drive updates.
+func NewChannelCAContentProvider(name string, bundles <-chan []byte, listeners ...Listener) CAContentProvider {
+	c := &channelCAContent{
+		name:      name,
+		listeners: listeners,
+	}
+	go c.run(bundles)
+	return c
+}
+
+func (c *channelCAContent) run(bundles <-chan []byte) {
+	for bundle := range bundles {
+		c.caBundle.Store(bundle)
+		for _, listener := range c.listeners {
+			listener.Enqueue()
+		}
+	}
+}
+
+// Name is just an identifier
+func (c *channelCAContent) Name() string {
+	return c.name
+}
+
+// CurrentCABundleContent provides ca bundle byte content. Empty until the first value is received on the channel.
+func (c *channelCAContent) CurrentCABundleContent() []byte {
+	bundle, _ := c.caBundle.Load().([]byte)
+	return bundle
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"testing"
+	"time"
+)
+
+// signalingListener is a Listener that signals a channel on every Enqueue, for deterministic test synchronization.
+type signalingListener struct {
+	enqueued chan struct{}
+}
+
+func newSignalingListener() *signalingListener {
+	return &signalingListener{enqueued: make(chan struct{}, 10)}
+}
+
+func (l *signalingListener) Enqueue() {
+	l.enqueued <- struct{}{}
+}
+
+func (l *signalingListener) waitForEnqueue(t *testing.T) {
+	t.Helper()
+	select {
+	case <-l.enqueued:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Enqueue")
+	}
+}
+
+func TestChannelCAContentProvider(t *testing.T) {
+	listener := newSignalingListener()
+	bundles := make(chan []byte)
+	provider := NewChannelCAContentProvider("test-channel", bundles, listener)
+
+	bundles <- []byte("bundle-1")
+	listener.waitForEnqueue(t)
+	if got := string(provider.CurrentCABundleContent()); got != "bundle-1" {
+		t.Errorf("expected the first bundle to flow through, got %q", got)
+	}
+
+	bundles <- []byte("bundle-2")
+	listener.waitForEnqueue(t)
+	if got := string(provider.CurrentCABundleContent()); got != "bundle-2" {
+		t.Errorf("expected the second bundle to flow through, got %q", got)
+	}
+
+	close(bundles)
+}
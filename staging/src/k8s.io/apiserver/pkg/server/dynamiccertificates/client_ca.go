@@ -30,10 +30,18 @@ type CAContentProvider interface {
 }
 
 // dynamicCertificateContent holds the content that overrides the baseTLSConfig
-// TODO add the serving certs to this struct
 type dynamicCertificateContent struct {
 	// clientCA holds the content for the clientCA bundle
 	clientCA caBundleContent
+	// ocspStaple holds the OCSP staple content to set on every served certificate
+	ocspStaple ocspStapleContent
+	// sessionTicketKeys holds the session ticket keys to install on the served tls.Config
+	sessionTicketKeys sessionTicketKeysContent
+	// servingCert holds the raw bytes of the serving certificate(s) that would be installed on the served
+	// tls.Config, so that a serving certificate rotation is itself detected as a content change
+	servingCert servingCertContent
+	// renegotiation holds the renegotiation policy to install on the served tls.Config
+	renegotiation renegotiationContent
 }
 
 // caBundleContent holds the content for the clientCA bundle.  Wrapping the bytes makes the Equals work nicely with the
@@ -51,6 +59,22 @@ func (c *dynamicCertificateContent) Equal(rhs *dynamicCertificateContent) bool {
 		return false
 	}
 
+	if !c.ocspStaple.Equal(&rhs.ocspStaple) {
+		return false
+	}
+
+	if !c.sessionTicketKeys.Equal(&rhs.sessionTicketKeys) {
+		return false
+	}
+
+	if !c.servingCert.Equal(&rhs.servingCert) {
+		return false
+	}
+
+	if !c.renegotiation.Equal(&rhs.renegotiation) {
+		return false
+	}
+
 	return true
 }
 
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+)
+
+// diffClientCACerts compares previous and current by subject and serial number, returning the certs present in
+// current but not previous (added) and the certs present in previous but not current (removed). A cert that's
+// present in both, even if reordered, appears in neither slice.
+func diffClientCACerts(previous, current []*x509.Certificate) (added, removed []*x509.Certificate) {
+	previousKeys := clientCACertKeySet(previous)
+	currentKeys := clientCACertKeySet(current)
+
+	for _, cert := range current {
+		if !previousKeys[clientCACertKey(cert)] {
+			added = append(added, cert)
+		}
+	}
+	for _, cert := range previous {
+		if !currentKeys[clientCACertKey(cert)] {
+			removed = append(removed, cert)
+		}
+	}
+	return added, removed
+}
+
+// clientCACertKey identifies a certificate by subject and serial number, the pair GetHumanCertDetail already
+// summarizes for a human reader, and enough to distinguish any two certs that aren't byte-for-byte identical for our
+// purposes here.
+func clientCACertKey(cert *x509.Certificate) string {
+	return cert.Subject.String() + "|" + cert.SerialNumber.String()
+}
+
+func clientCACertKeySet(certs []*x509.Certificate) map[string]bool {
+	keys := make(map[string]bool, len(certs))
+	for _, cert := range certs {
+		keys[clientCACertKey(cert)] = true
+	}
+	return keys
+}
+
+// describeClientCACertDiff summarizes added/removed for the ClientCABundleChanged event's note.
+func describeClientCACertDiff(added, removed []*x509.Certificate) string {
+	return fmt.Sprintf("client CA bundle changed: added [%s], removed [%s]", describeClientCACerts(added), describeClientCACerts(removed))
+}
+
+func describeClientCACerts(certs []*x509.Certificate) string {
+	if len(certs) == 0 {
+		return "none"
+	}
+	descriptions := make([]string, 0, len(certs))
+	for _, cert := range certs {
+		descriptions = append(descriptions, fmt.Sprintf("%s (serial %s)", cert.Subject, cert.SerialNumber))
+	}
+	return strings.Join(descriptions, ", ")
+}
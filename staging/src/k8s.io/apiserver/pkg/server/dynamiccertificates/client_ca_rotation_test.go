@@ -0,0 +1,203 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// testCA bundles a self-signed CA certificate with its private key, so a test can both trust the certificate (via
+// its PEM encoding) and use the key to sign a client certificate issued by it.
+type testCA struct {
+	certPEM []byte
+	cert    *x509.Certificate
+	key     *rsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, commonName string) testCA {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCA{
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		cert:    cert,
+		key:     key,
+	}
+}
+
+// newTestClientCert returns a client certificate issued by ca.
+func newTestClientCert(t *testing.T, ca testCA, commonName string) tls.Certificate {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// echoLoop copies whatever it reads from conn back to conn until either side closes it, so a test can prove a
+// connection is still functional well after it was established.
+func echoLoop(conn net.Conn) {
+	defer conn.Close()
+	buf := make([]byte, 32)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		if _, err := conn.Write(buf[:n]); err != nil {
+			return
+		}
+	}
+}
+
+// TestClientCARotationDoesNotAffectExistingConnections confirms that rotating the served client CA only changes
+// which issuer a *new* connection's handshake is verified against; an already-established connection, verified
+// under the old CA, is unaffected, since crypto/tls never re-verifies a client's certificate chain after the initial
+// handshake completes. That's a property of Go's tls package, not of this controller, but is worth pinning down with
+// a regression test given how easy it would be to break by, say, wiring in per-connection re-verification.
+func TestClientCARotationDoesNotAffectExistingConnections(t *testing.T) {
+	caA := newTestCA(t, "ca-a")
+	caB := newTestCA(t, "ca-b")
+	clientCertA := newTestClientCert(t, caA, "client-a")
+
+	clientCA := &mutableCAContent{name: "test-ca", bundle: caA.certPEM}
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{
+			Certificates: []tls.Certificate{newTestServingCert(t, "127.0.0.1")},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+		},
+		clientCA: clientCA,
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{GetConfigForClient: c.GetConfigForClient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go echoLoop(conn)
+		}
+	}()
+
+	dial := func(cert tls.Certificate) (*tls.Conn, error) {
+		return tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+			Certificates:       []tls.Certificate{cert},
+			InsecureSkipVerify: true,
+		})
+	}
+
+	firstConn, err := dial(clientCertA)
+	if err != nil {
+		t.Fatalf("expected the first connection, trusted by CA A, to succeed: %v", err)
+	}
+	defer firstConn.Close()
+	if err := roundTrip(firstConn, "ping1"); err != nil {
+		t.Fatalf("unexpected error on the first connection before rotation: %v", err)
+	}
+
+	// Rotate the served client CA so it trusts CA B instead of CA A.
+	clientCA.bundle = caB.certPEM
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := roundTrip(firstConn, "ping2"); err != nil {
+		t.Errorf("expected the already-established connection to remain usable after rotating the client CA, got: %v", err)
+	}
+
+	// A new connection still dials successfully: TLS's client side only reports its own handshake steps, and the
+	// server's rejection of a certificate it no longer trusts doesn't surface until the connection is actually used.
+	secondConn, err := dial(clientCertA)
+	if err != nil {
+		t.Fatalf("expected the dial itself to succeed even though the server will reject the connection: %v", err)
+	}
+	defer secondConn.Close()
+	if err := roundTrip(secondConn, "ping3"); err == nil {
+		t.Error("expected a new connection presenting CA A's client certificate to be rejected after rotating to CA B")
+	}
+}
+
+// roundTrip writes msg to conn and asserts the echoed reply matches.
+func roundTrip(conn net.Conn, msg string) error {
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+	buf := make([]byte, len(msg))
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return err
+	}
+	if string(buf) != msg {
+		return fmt.Errorf("expected echo %q, got %q", msg, buf)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+)
+
+// combinedPEMProvider is both a CAContentProvider and a ServingCertProvider backed by a single PEM file that
+// contains a CA bundle, a serving certificate, and its private key all concatenated together, for tools that emit
+// everything as one file rather than the usual separate cert/key/CA files.
+type combinedPEMProvider struct {
+	name     string
+	caBundle []byte
+	certs    []tls.Certificate
+}
+
+// NewCombinedPEMProviderFromFile returns a combinedPEMProvider parsed from the PEM file at filename.
+func NewCombinedPEMProviderFromFile(filename string) (*combinedPEMProvider, error) {
+	if len(filename) == 0 {
+		return nil, fmt.Errorf("missing filename for combined PEM")
+	}
+
+	combined, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return NewCombinedPEMProvider(filename, combined)
+}
+
+// NewCombinedPEMProvider parses combined, a PEM file containing a CA bundle, a serving certificate, and its private
+// key all concatenated together, routing the CA certificates to CurrentCABundleContent and the leaf certificate plus
+// key to CurrentServingCertificate. It returns an error unless combined contains exactly one private key and
+// exactly one non-CA certificate that pairs with it; CA certificates are optional.
+func NewCombinedPEMProvider(name string, combined []byte) (*combinedPEMProvider, error) {
+	var caCerts []*pem.Block
+	var leafCert *pem.Block
+	var keys []*pem.Block
+
+	rest := combined
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		switch {
+		case block.Type == "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("invalid certificate in combined PEM: %v", err)
+			}
+			if cert.IsCA {
+				caCerts = append(caCerts, block)
+				continue
+			}
+			if leafCert != nil {
+				return nil, fmt.Errorf("combined PEM contains more than one non-CA (serving) certificate")
+			}
+			leafCert = block
+
+		case block.Type == "RSA PRIVATE KEY", block.Type == "EC PRIVATE KEY", block.Type == "PRIVATE KEY":
+			keys = append(keys, block)
+		}
+	}
+
+	if len(keys) != 1 {
+		return nil, fmt.Errorf("combined PEM must contain exactly one private key, found %d", len(keys))
+	}
+	if leafCert == nil {
+		return nil, fmt.Errorf("combined PEM does not contain a serving certificate")
+	}
+
+	servingCertPEM := pem.EncodeToMemory(leafCert)
+	keyPEM := pem.EncodeToMemory(keys[0])
+	servingCert, err := tls.X509KeyPair(servingCertPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid serving certificate/key pair in combined PEM: %v", err)
+	}
+	servingCert.Leaf, err = x509.ParseCertificate(servingCert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid serving certificate in combined PEM: %v", err)
+	}
+
+	var caBundle bytes.Buffer
+	for _, block := range caCerts {
+		caBundle.Write(pem.EncodeToMemory(block))
+	}
+
+	return &combinedPEMProvider{
+		name:     name,
+		caBundle: caBundle.Bytes(),
+		certs:    []tls.Certificate{servingCert},
+	}, nil
+}
+
+// Name is just an identifier
+func (p *combinedPEMProvider) Name() string {
+	return p.name
+}
+
+// CurrentCABundleContent provides the CA certificates parsed out of the combined PEM.
+func (p *combinedPEMProvider) CurrentCABundleContent() []byte {
+	return p.caBundle
+}
+
+// CurrentServingCertificate returns the serving certificate/key pair parsed out of the combined PEM.
+func (p *combinedPEMProvider) CurrentServingCertificate() []tls.Certificate {
+	return p.certs
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+// newTestCombinedPEM concatenates a CA certificate PEM with a serving certificate and its private key, mimicking
+// what a tool that emits one combined file would produce.
+func newTestCombinedPEM(t *testing.T, dnsName string) []byte {
+	t.Helper()
+	caPEM := newTestCACertPEM(t, "combined-test-ca")
+	servingCert := newTestServingCert(t, dnsName)
+
+	var combined []byte
+	combined = append(combined, caPEM...)
+	combined = append(combined, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: servingCert.Certificate[0]})...)
+	combined = append(combined, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(servingCert.PrivateKey.(*rsa.PrivateKey)),
+	})...)
+	return combined
+}
+
+func TestNewCombinedPEMProviderParsesCAAndServingCert(t *testing.T) {
+	combined := newTestCombinedPEM(t, "combined.example.com")
+
+	provider, err := NewCombinedPEMProvider("combined", combined)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	caBundle := provider.CurrentCABundleContent()
+	if len(caBundle) == 0 {
+		t.Error("expected a non-empty CA bundle")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBundle) {
+		t.Error("expected the parsed CA bundle to contain a valid certificate")
+	}
+
+	certs := provider.CurrentServingCertificate()
+	if len(certs) != 1 {
+		t.Fatalf("expected exactly one serving certificate, got %d", len(certs))
+	}
+	if err := certs[0].Leaf.VerifyHostname("combined.example.com"); err != nil {
+		t.Errorf("unexpected error verifying serving cert hostname: %v", err)
+	}
+}
+
+func TestNewCombinedPEMProviderRejectsMissingOrDuplicateKeys(t *testing.T) {
+	caOnly := newTestCACertPEM(t, "ca-only")
+	if _, err := NewCombinedPEMProvider("ca-only", caOnly); err == nil {
+		t.Error("expected an error for a combined PEM missing a private key")
+	}
+
+	combined := newTestCombinedPEM(t, "combined.example.com")
+	duplicateKey := append(append([]byte{}, combined...), combined...)
+	if _, err := NewCombinedPEMProvider("duplicate-key", duplicateKey); err == nil {
+		t.Error("expected an error for a combined PEM with more than one private key")
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentForceSyncAndReconfigure drives ForceSync and ReconfigureAll from many goroutines at once, so
+// `go test -race` catches any data race over the controller's shared state (currentlyServedContent,
+// currentClientCACerts, baseTLSConfig, clientCA). It doesn't assert on the served content, since which of the
+// racing ReconfigureAll calls "wins" is inherently nondeterministic; the point is that no run may race.
+func TestConcurrentForceSyncAndReconfigure(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:      NewStaticCAContent("initial-ca", newTestCACertPEM(t, "initial")),
+	}
+	if err := c.ForceSync(); err != nil {
+		t.Fatalf("unexpected error priming the controller: %v", err)
+	}
+
+	// newTestServingCert and newTestCACertPEM call t.Fatal on error, which per testing.T's contract may only
+	// happen on the test's own goroutine -- so generate every rotated cert/CA here, before spawning, rather than
+	// inside the goroutines below.
+	newConfigs := make([]*tls.Config, 10)
+	newCAs := make([]CAContentProvider, 10)
+	for i := range newConfigs {
+		newConfigs[i] = &tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}}
+		newCAs[i] = NewStaticCAContent("rotated-ca", newTestCACertPEM(t, "rotated"))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := c.ForceSync(); err != nil {
+				t.Errorf("unexpected error from ForceSync: %v", err)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			if err := c.ReconfigureAll(newConfigs[i], newCAs[i], nil); err != nil {
+				t.Errorf("unexpected error from ReconfigureAll: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if _, err := c.GetConfigForClient(&tls.ClientHelloInfo{}); err != nil {
+		t.Fatalf("unexpected error reading final config: %v", err)
+	}
+}
+
+// TestConcurrentContentHistoryAndSync drives ContentHistory and ForceSync from many goroutines at once, so
+// `go test -race` catches a read of contentHistory that isn't synchronized against recordContentChange's
+// write-locked appends.
+func TestConcurrentContentHistoryAndSync(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:     tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:          NewStaticCAContent("initial-ca", newTestCACertPEM(t, "initial")),
+		MaxContentHistory: 5,
+	}
+	if err := c.ForceSync(); err != nil {
+		t.Fatalf("unexpected error priming the controller: %v", err)
+	}
+
+	rotatedCAs := make([]CAContentProvider, 50)
+	for i := range rotatedCAs {
+		rotatedCAs[i] = NewStaticCAContent("rotated-ca", newTestCACertPEM(t, "rotated"))
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for _, ca := range rotatedCAs {
+			if err := c.ReconfigureAll(&c.baseTLSConfig, ca, nil); err != nil {
+				t.Errorf("unexpected error from ReconfigureAll: %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = c.ContentHistory()
+			}
+		}
+	}()
+	wg.Wait()
+}
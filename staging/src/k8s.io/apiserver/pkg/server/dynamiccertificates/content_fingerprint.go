@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+)
+
+// CurrentContentFingerprint returns a stable hex-encoded SHA-256 hash over every piece of dynamic content this
+// controller is currently serving: the client CA bundle, the serving certificate(s), the OCSP staple, the session
+// ticket keys, and the renegotiation policy. Two controllers (e.g. HA peers, or the same controller before and after a sync) that are
+// serving identical content always return identical fingerprints; any divergence in any one of those inputs changes
+// the result. Returns the fingerprint of an all-empty configuration before the first successful sync.
+func (c *DynamicServingCertificateController) CurrentContentFingerprint() string {
+	hash := sha256.New()
+
+	c.mu.RLock()
+	content := c.currentlyServedContent
+	c.mu.RUnlock()
+	if content != nil {
+		hash.Write(content.clientCA.caBundle)
+		hash.Write(content.ocspStaple.staple)
+		for _, key := range content.sessionTicketKeys.keys {
+			hash.Write(key[:])
+		}
+		hash.Write([]byte{byte(content.renegotiation.support)})
+	}
+
+	if uncastObj := c.currentServingTLSConfig.Load(); uncastObj != nil {
+		if tlsConfig, ok := uncastObj.(*tls.Config); ok {
+			for _, cert := range tlsConfig.Certificates {
+				for _, der := range cert.Certificate {
+					hash.Write(der)
+				}
+			}
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestCurrentContentFingerprintMatchesAcrossIdenticalControllers(t *testing.T) {
+	caBundle := newTestCACertPEM(t, "test-ca")
+	servingCert := newTestServingCert(t, "example.com")
+
+	newController := func() *DynamicServingCertificateController {
+		return &DynamicServingCertificateController{
+			baseTLSConfig: tls.Config{Certificates: []tls.Certificate{servingCert}},
+			clientCA:      NewStaticCAContent("test-ca", caBundle),
+		}
+	}
+
+	c1 := newController()
+	if err := c1.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c2 := newController()
+	if err := c2.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c1.CurrentContentFingerprint() != c2.CurrentContentFingerprint() {
+		t.Error("expected two controllers serving identical content to have identical fingerprints")
+	}
+}
+
+func TestCurrentContentFingerprintDiffersWhenContentDiffers(t *testing.T) {
+	servingCert := newTestServingCert(t, "example.com")
+
+	c1 := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{Certificates: []tls.Certificate{servingCert}},
+		clientCA:      NewStaticCAContent("ca-one", newTestCACertPEM(t, "ca-one")),
+	}
+	if err := c1.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c2 := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{Certificates: []tls.Certificate{servingCert}},
+		clientCA:      NewStaticCAContent("ca-two", newTestCACertPEM(t, "ca-two")),
+	}
+	if err := c2.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c1.CurrentContentFingerprint() == c2.CurrentContentFingerprint() {
+		t.Error("expected controllers serving different client CA bundles to have different fingerprints")
+	}
+}
+
+func TestCurrentContentFingerprintBeforeFirstSync(t *testing.T) {
+	c := &DynamicServingCertificateController{}
+	if c.CurrentContentFingerprint() == "" {
+		t.Error("expected a non-empty fingerprint even before the first sync")
+	}
+}
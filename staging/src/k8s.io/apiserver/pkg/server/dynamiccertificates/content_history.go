@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"time"
+)
+
+// ContentChange records one successful syncCerts content swap, for auditors who need a record of every CA/serving
+// cert rotation rather than just the currently active one.
+type ContentChange struct {
+	// Time is when this content became the one being served.
+	Time time.Time
+	// ClientCAProviderName is the Name() of the effective client CA provider (clientCA unioned with any
+	// AddCAProvider registrations) at the time of this change.
+	ClientCAProviderName string
+	// ClientCAFingerprints are the hex SHA-256 fingerprints of the certs trusted in the new client CA pool, in the
+	// order they were loaded.
+	ClientCAFingerprints []string
+	// ServingCertFingerprints are the hex SHA-256 fingerprints of the leafs of the newly served config's
+	// Certificates, whether they came from baseTLSConfig, ServingCertProvider or MaintenanceCert.
+	ServingCertFingerprints []string
+}
+
+// ContentHistory returns the recorded ContentChanges, oldest first, up to MaxContentHistory entries. Empty unless
+// MaxContentHistory is set to a positive value.
+func (c *DynamicServingCertificateController) ContentHistory() []ContentChange {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]ContentChange(nil), c.contentHistory...)
+}
+
+// recordContentChange appends a ContentChange for the content syncCerts just started serving, trimming the ring
+// buffer down to MaxContentHistory entries. It's a no-op if MaxContentHistory isn't positive.
+func (c *DynamicServingCertificateController) recordContentChange(clientCACerts []*x509.Certificate, servingCerts []tls.Certificate) {
+	if c.MaxContentHistory <= 0 {
+		return
+	}
+
+	change := ContentChange{
+		Time:                 c.now(),
+		ClientCAProviderName: c.effectiveClientCA().Name(),
+	}
+	for _, cert := range clientCACerts {
+		change.ClientCAFingerprints = append(change.ClientCAFingerprints, fingerprintCert(cert.Raw))
+	}
+	for _, tlsCert := range servingCerts {
+		if leaf := leafCertificate(tlsCert); leaf != nil {
+			change.ServingCertFingerprints = append(change.ServingCertFingerprints, fingerprintCert(leaf.Raw))
+		}
+	}
+
+	c.contentHistory = append(c.contentHistory, change)
+	if len(c.contentHistory) > c.MaxContentHistory {
+		c.contentHistory = c.contentHistory[len(c.contentHistory)-c.MaxContentHistory:]
+	}
+}
+
+// fingerprintCert returns the hex-encoded SHA-256 fingerprint of a DER-encoded certificate.
+func fingerprintCert(certDER []byte) string {
+	sum := sha256.Sum256(certDER)
+	return hex.EncodeToString(sum[:])
+}
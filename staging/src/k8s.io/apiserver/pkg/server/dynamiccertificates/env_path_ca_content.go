@@ -0,0 +1,61 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"io/ioutil"
+	"os"
+
+	"k8s.io/klog"
+)
+
+// EnvPathCAContentProvider is a CAContentProvider that resolves its backing file path from an environment variable
+// on every read, instead of once at construction, so the file's location can change (e.g. a redeployed container
+// mounting the bundle somewhere new) without recreating the provider. An unset env var, or a file that can't be
+// read, logs a warning and returns no content rather than erroring, consistent with CurrentCABundleContent's
+// contract that it never fails.
+type EnvPathCAContentProvider struct {
+	name   string
+	envVar string
+}
+
+// NewEnvPathCAContentProvider returns an EnvPathCAContentProvider named name that reads its CA bundle from the file
+// named by envVar's current value, re-resolved on every call to CurrentCABundleContent.
+func NewEnvPathCAContentProvider(name, envVar string) *EnvPathCAContentProvider {
+	return &EnvPathCAContentProvider{name: name, envVar: envVar}
+}
+
+// Name is just an identifier
+func (c *EnvPathCAContentProvider) Name() string {
+	return c.name
+}
+
+// CurrentCABundleContent re-resolves c.envVar and reads the CA bundle from the file it currently names.
+func (c *EnvPathCAContentProvider) CurrentCABundleContent() []byte {
+	path := os.Getenv(c.envVar)
+	if len(path) == 0 {
+		klog.Warningf("dynamiccertificates: %q is unset, CA content provider %q has no bundle", c.envVar, c.name)
+		return nil
+	}
+
+	caBundle, err := ioutil.ReadFile(path)
+	if err != nil {
+		klog.Warningf("dynamiccertificates: CA content provider %q failed to read %q from %s: %v", c.name, path, c.envVar, err)
+		return nil
+	}
+	return caBundle
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvPathCAContentProviderReadsCurrentEnvValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "env-path-ca-content")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	firstPath := filepath.Join(dir, "first.crt")
+	if err := ioutil.WriteFile(firstPath, []byte("first-bundle"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	secondPath := filepath.Join(dir, "second.crt")
+	if err := ioutil.WriteFile(secondPath, []byte("second-bundle"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const envVar = "TEST_ENV_PATH_CA_CONTENT_BUNDLE"
+	defer os.Unsetenv(envVar)
+
+	c := NewEnvPathCAContentProvider("test", envVar)
+
+	os.Setenv(envVar, firstPath)
+	if got := c.CurrentCABundleContent(); !bytes.Equal(got, []byte("first-bundle")) {
+		t.Errorf("expected first-bundle, got %q", got)
+	}
+
+	os.Setenv(envVar, secondPath)
+	if got := c.CurrentCABundleContent(); !bytes.Equal(got, []byte("second-bundle")) {
+		t.Errorf("expected second-bundle after the env var is redirected, got %q", got)
+	}
+}
+
+func TestEnvPathCAContentProviderUnsetEnvVar(t *testing.T) {
+	const envVar = "TEST_ENV_PATH_CA_CONTENT_UNSET"
+	os.Unsetenv(envVar)
+
+	c := NewEnvPathCAContentProvider("test", envVar)
+	if got := c.CurrentCABundleContent(); got != nil {
+		t.Errorf("expected no content for an unset env var, got %q", got)
+	}
+}
+
+func TestEnvPathCAContentProviderMissingFile(t *testing.T) {
+	const envVar = "TEST_ENV_PATH_CA_CONTENT_MISSING"
+	defer os.Unsetenv(envVar)
+	os.Setenv(envVar, "/does/not/exist")
+
+	c := NewEnvPathCAContentProvider("test", envVar)
+	if got := c.CurrentCABundleContent(); got != nil {
+		t.Errorf("expected no content when the file doesn't exist, got %q", got)
+	}
+}
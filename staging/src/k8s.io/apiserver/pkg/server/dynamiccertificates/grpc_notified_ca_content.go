@@ -0,0 +1,155 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+// RotationNotificationStream is the minimal shape of a gRPC client stream carrying rotation push notifications: call
+// Recv in a loop, treating a returned error as the stream having ended (whether cleanly or not). A generated gRPC
+// client stream's Recv method satisfies this without modification; the actual notification payload, if any, is
+// irrelevant here since a notification only ever means "re-read the bundle."
+type RotationNotificationStream interface {
+	Recv() error
+}
+
+// RotationStreamDialer opens a fresh RotationNotificationStream, e.g. by calling a generated gRPC client's
+// subscribe-to-rotations RPC. It's called again, with backoff, every time the previous stream ends or fails to open.
+type RotationStreamDialer func() (RotationNotificationStream, error)
+
+// CABundleReader re-reads the current CA bundle from whatever backing store a push notification's rotation actually
+// landed in (e.g. a mounted secret refreshed out-of-band by the same rotation). Called once up front and again after
+// every notification received on the stream.
+type CABundleReader func() ([]byte, error)
+
+// grpcNotifiedCAContent is a CAContentProvider that re-reads its bundle from a CABundleReader every time a
+// RotationNotificationStream delivers a notification, reconnecting the stream with backoff whenever it ends.
+type grpcNotifiedCAContent struct {
+	name    string
+	dial    RotationStreamDialer
+	read    CABundleReader
+	backoff wait.Backoff
+
+	caBundle atomic.Value // holds []byte
+
+	listeners []Listener
+}
+
+// NewGRPCNotifiedCAContentProvider returns a CAContentProvider that reads the initial bundle via read, then keeps it
+// current by dialing a RotationNotificationStream via dial and re-reading after each notification it delivers. If
+// the stream ends or dial fails, it reconnects after backoff, resetting backoff on every successful dial. stopCh is
+// checked before each dial and during each backoff wait; a stream blocked in Recv is not interrupted by it. Returns
+// an error if the initial read fails, since a provider with no bundle at all isn't useful to construct.
+func NewGRPCNotifiedCAContentProvider(name string, dial RotationStreamDialer, read CABundleReader, backoff wait.Backoff, stopCh <-chan struct{}, listeners ...Listener) (CAContentProvider, error) {
+	c := &grpcNotifiedCAContent{
+		name:      name,
+		dial:      dial,
+		read:      read,
+		backoff:   backoff,
+		listeners: listeners,
+	}
+	if err := c.readAndStore(); err != nil {
+		return nil, fmt.Errorf("unable to read initial CA bundle for %q: %w", name, err)
+	}
+
+	go c.run(stopCh)
+
+	return c, nil
+}
+
+// run dials the notification stream and drains it until it ends, reconnecting with backoff, until stopCh is closed.
+func (c *grpcNotifiedCAContent) run(stopCh <-chan struct{}) {
+	backoff := c.backoff
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+
+		stream, err := c.dial()
+		if err != nil {
+			klog.Warningf("dynamiccertificates: failed to open rotation notification stream for %q, retrying: %v", c.name, err)
+			if !c.sleep(backoff.Step(), stopCh) {
+				return
+			}
+			continue
+		}
+		backoff = c.backoff
+
+		for {
+			if err := stream.Recv(); err != nil {
+				klog.Warningf("dynamiccertificates: rotation notification stream for %q ended, reconnecting: %v", c.name, err)
+				break
+			}
+			if err := c.readAndStore(); err != nil {
+				klog.Warningf("dynamiccertificates: failed to re-read CA bundle for %q after rotation notification: %v", c.name, err)
+			}
+		}
+
+		if !c.sleep(backoff.Step(), stopCh) {
+			return
+		}
+	}
+}
+
+// sleep waits for d or stopCh, whichever comes first, reporting whether it was d (true) or stopCh (false).
+func (c *grpcNotifiedCAContent) sleep(d time.Duration, stopCh <-chan struct{}) bool {
+	select {
+	case <-stopCh:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// readAndStore reads the current bundle and, if it differs from what's already stored, stores it and enqueues every
+// listener.
+func (c *grpcNotifiedCAContent) readAndStore() error {
+	bundle, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	previous, _ := c.caBundle.Load().([]byte)
+	if bytes.Equal(previous, bundle) {
+		return nil
+	}
+	c.caBundle.Store(bundle)
+	for _, listener := range c.listeners {
+		listener.Enqueue()
+	}
+	return nil
+}
+
+// Name is just an identifier
+func (c *grpcNotifiedCAContent) Name() string {
+	return c.name
+}
+
+// CurrentCABundleContent provides the last bundle read, either at construction or after a rotation notification.
+func (c *grpcNotifiedCAContent) CurrentCABundleContent() []byte {
+	bundle, _ := c.caBundle.Load().([]byte)
+	return bundle
+}
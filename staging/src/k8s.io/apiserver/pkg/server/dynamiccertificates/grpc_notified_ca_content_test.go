@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// fakeRotationStream is a RotationNotificationStream backed by a caller-owned channel: a send delivers a
+// notification, closing the channel ends the stream.
+type fakeRotationStream struct {
+	notifications <-chan struct{}
+}
+
+func (s *fakeRotationStream) Recv() error {
+	if _, ok := <-s.notifications; !ok {
+		return errors.New("fake rotation stream closed")
+	}
+	return nil
+}
+
+func TestGRPCNotifiedCAContentRefreshesOnNotification(t *testing.T) {
+	var mu sync.Mutex
+	bundle := []byte("bundle-v1")
+	notifications := make(chan struct{})
+
+	dial := func() (RotationNotificationStream, error) {
+		return &fakeRotationStream{notifications: notifications}, nil
+	}
+	read := func() ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return bundle, nil
+	}
+
+	listener := &countingListener{}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	provider, err := NewGRPCNotifiedCAContentProvider("test-grpc", dial, read, wait.Backoff{Duration: 10 * time.Millisecond}, stopCh, listener)
+	if err != nil {
+		t.Fatalf("unexpected error on initial read: %v", err)
+	}
+
+	if got := provider.CurrentCABundleContent(); !bytes.Equal(got, []byte("bundle-v1")) {
+		t.Fatalf("expected the initial bundle to be served, got %q", got)
+	}
+
+	mu.Lock()
+	bundle = []byte("bundle-v2")
+	mu.Unlock()
+
+	notifications <- struct{}{}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		if bytes.Equal(provider.CurrentCABundleContent(), []byte("bundle-v2")) {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for the refreshed bundle to be served, last saw %q", provider.CurrentCABundleContent())
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	if atomic.LoadInt32(&listener.count) == 0 {
+		t.Error("expected at least one listener Enqueue call after the notified bundle changed")
+	}
+}
+
+func TestGRPCNotifiedCAContentReconnectsAfterStreamEnds(t *testing.T) {
+	var mu sync.Mutex
+	bundle := []byte("bundle-v1")
+	var dials int32
+
+	dial := func() (RotationNotificationStream, error) {
+		atomic.AddInt32(&dials, 1)
+		notifications := make(chan struct{})
+		close(notifications) // Recv fails immediately, forcing a reconnect.
+		return &fakeRotationStream{notifications: notifications}, nil
+	}
+	read := func() ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return bundle, nil
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	if _, err := NewGRPCNotifiedCAContentProvider("test-grpc", dial, read, wait.Backoff{Duration: time.Millisecond}, stopCh); err != nil {
+		t.Fatalf("unexpected error on initial read: %v", err)
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		if atomic.LoadInt32(&dials) >= 3 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for reconnect attempts, saw %d", atomic.LoadInt32(&dials))
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestGRPCNotifiedCAContentInitialReadFailure(t *testing.T) {
+	dial := func() (RotationNotificationStream, error) {
+		return nil, errors.New("should not be dialed")
+	}
+	read := func() ([]byte, error) {
+		return nil, errors.New("initial read failed")
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	if _, err := NewGRPCNotifiedCAContentProvider("test-grpc", dial, read, wait.Backoff{Duration: time.Second}, stopCh); err == nil {
+		t.Error("expected an error when the initial read fails")
+	}
+}
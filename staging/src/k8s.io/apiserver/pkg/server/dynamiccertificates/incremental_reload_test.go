@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// countingDecoder wraps PEMBundleDecoder and counts how many times it's actually invoked, so tests and benchmarks
+// can assert whether a sync re-parsed the client CA bundle or reused the previous result.
+func countingDecoder(calls *int) func([]byte) ([]*x509.Certificate, error) {
+	return func(pemBytes []byte) ([]*x509.Certificate, error) {
+		*calls++
+		return PEMBundleDecoder(pemBytes)
+	}
+}
+
+// newTestServingCertTB is newTestServingCert's testing.TB counterpart, needed so BenchmarkSyncCertsServingCertOnlyChange
+// can mint the rotated certificates it benchmarks against with *testing.B.
+func newTestServingCertTB(t testing.TB, dnsNames ...string) tls.Certificate {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "serving"},
+		DNSNames:              dnsNames,
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestSyncCertsReusesClientCAPoolWhenOnlyServingCertChanges(t *testing.T) {
+	var decodeCalls int
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:         tls.Config{},
+		clientCA:              NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		ClientCABundleDecoder: countingDecoder(&decodeCalls),
+		ServingCertProvider:   NewStaticServingCertProvider("serving-cert", []tls.Certificate{newTestServingCert(t, "one.example.com")}),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error on first sync: %v", err)
+	}
+	if decodeCalls != 1 {
+		t.Fatalf("expected the first sync to decode the client CA bundle exactly once, got %d", decodeCalls)
+	}
+	firstPool := c.currentClientCACerts
+
+	c.ServingCertProvider = NewStaticServingCertProvider("serving-cert", []tls.Certificate{newTestServingCert(t, "two.example.com")})
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error on second sync: %v", err)
+	}
+	if decodeCalls != 1 {
+		t.Errorf("expected a serving-cert-only change to reuse the already-parsed client CA pool without re-decoding, got %d total decode calls", decodeCalls)
+	}
+
+	tlsConfig, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.Certificates[0].Leaf.DNSNames[0] != "two.example.com" {
+		t.Errorf("expected the rotated serving certificate to still be served, got DNS name %q", tlsConfig.Certificates[0].Leaf.DNSNames[0])
+	}
+	if len(c.currentClientCACerts) != len(firstPool) {
+		t.Errorf("expected the reused client CA cert slice to be unchanged, got %d certs, want %d", len(c.currentClientCACerts), len(firstPool))
+	}
+}
+
+func TestSyncCertsReDecodesWhenClientCABundleChanges(t *testing.T) {
+	var decodeCalls int
+	clientCA := NewStaticCAContent("test-ca", newTestCACertPEM(t, "first"))
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:         tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:              clientCA,
+		ClientCABundleDecoder: countingDecoder(&decodeCalls),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error on first sync: %v", err)
+	}
+
+	c.clientCA = NewStaticCAContent("test-ca", newTestCACertPEM(t, "second"))
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error on second sync: %v", err)
+	}
+	if decodeCalls != 2 {
+		t.Errorf("expected an actual client CA bundle change to trigger a re-decode, got %d total decode calls", decodeCalls)
+	}
+}
+
+// BenchmarkSyncCertsServingCertOnlyChange rotates only the serving certificate on every sync, to demonstrate that
+// reusing the client CA pool avoids repeatedly re-decoding and re-validating the same bundle.
+func BenchmarkSyncCertsServingCertOnlyChange(b *testing.B) {
+	caBundle := newTestCACertPEM(b, "bench-ca")
+	certs := make([]tls.Certificate, b.N)
+	for i := range certs {
+		certs[i] = newTestServingCertTB(b, "example.com")
+	}
+
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:       tls.Config{},
+		clientCA:            NewStaticCAContent("bench-ca", caBundle),
+		ServingCertProvider: NewStaticServingCertProvider("serving-cert", []tls.Certificate{certs[0]}),
+	}
+	if err := c.RunOnce(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 1; i < b.N; i++ {
+		c.ServingCertProvider = NewStaticServingCertProvider("serving-cert", []tls.Certificate{certs[i]})
+		if err := c.RunOnce(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
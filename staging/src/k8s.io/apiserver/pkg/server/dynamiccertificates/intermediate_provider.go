@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+)
+
+// IntermediateProvider supplies additional DER-encoded intermediate certificates that syncCerts appends to every
+// serving certificate's chain, for a leaf that's issued (e.g. by an external CA) without its intermediates bundled
+// in, which otherwise fails validation for a client that doesn't already trust the leaf's immediate issuer directly.
+type IntermediateProvider interface {
+	// Name is just an identifier
+	Name() string
+	// CurrentIntermediateCertificates returns the current DER-encoded intermediate certificate(s), in the order
+	// they should appear after the leaf, or nil if none are configured.
+	CurrentIntermediateCertificates() [][]byte
+}
+
+type staticIntermediateProvider struct {
+	name  string
+	certs [][]byte
+}
+
+// NewStaticIntermediateProvider returns an IntermediateProvider that always returns the same intermediate
+// certificate(s).
+func NewStaticIntermediateProvider(name string, certs [][]byte) IntermediateProvider {
+	return &staticIntermediateProvider{name: name, certs: certs}
+}
+
+// Name is just an identifier
+func (p *staticIntermediateProvider) Name() string {
+	return p.name
+}
+
+// CurrentIntermediateCertificates returns the static intermediate certificate(s).
+func (p *staticIntermediateProvider) CurrentIntermediateCertificates() [][]byte {
+	return p.certs
+}
+
+// appendIntermediateCertificates returns a copy of certs with provider's intermediates appended after each entry's
+// existing chain, so a leaf served without its intermediates bundled in presents a complete chain. A nil provider,
+// or one currently supplying no intermediates, returns certs unchanged.
+func appendIntermediateCertificates(certs []tls.Certificate, provider IntermediateProvider) []tls.Certificate {
+	if provider == nil {
+		return certs
+	}
+	intermediates := provider.CurrentIntermediateCertificates()
+	if len(intermediates) == 0 {
+		return certs
+	}
+
+	out := make([]tls.Certificate, len(certs))
+	for i, cert := range certs {
+		chain := make([][]byte, 0, len(cert.Certificate)+len(intermediates))
+		chain = append(chain, cert.Certificate...)
+		chain = append(chain, intermediates...)
+		cert.Certificate = chain
+		out[i] = cert
+	}
+	return out
+}
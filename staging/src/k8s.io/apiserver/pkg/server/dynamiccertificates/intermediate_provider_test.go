@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"bytes"
+	"crypto/tls"
+	"testing"
+)
+
+func TestSyncCertsAssemblesCompleteChainFromIntermediateProvider(t *testing.T) {
+	leaf := newTestServingCert(t, "example.com")
+	intermediateDER := newTestServingCert(t, "intermediate.example.com").Certificate[0]
+
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:        tls.Config{Certificates: []tls.Certificate{leaf}},
+		clientCA:             NewStaticCAContent("test-ca", newTestCACertPEM(t, "ca")),
+		IntermediateProvider: NewStaticIntermediateProvider("test-intermediate", [][]byte{intermediateDER}),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	served, ok := c.currentServingTLSConfig.Load().(*tls.Config)
+	if !ok || len(served.Certificates) != 1 {
+		t.Fatalf("expected exactly one served certificate, got %#v", served)
+	}
+	chain := served.Certificates[0].Certificate
+	if len(chain) != 2 {
+		t.Fatalf("expected a 2-certificate chain (leaf + intermediate), got %d", len(chain))
+	}
+	if !bytes.Equal(chain[0], leaf.Certificate[0]) {
+		t.Error("expected the first certificate in the chain to be the original leaf")
+	}
+	if !bytes.Equal(chain[1], intermediateDER) {
+		t.Error("expected the second certificate in the chain to be the supplied intermediate")
+	}
+}
+
+func TestAppendIntermediateCertificatesNilProviderNoOp(t *testing.T) {
+	certs := []tls.Certificate{{Certificate: [][]byte{[]byte("leaf")}}}
+	got := appendIntermediateCertificates(certs, nil)
+	if len(got) != 1 || len(got[0].Certificate) != 1 {
+		t.Errorf("expected certs unchanged with a nil provider, got %#v", got)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+// IssuerCAFetcher retrieves the current CA bundle bytes from wherever the cluster's OIDC issuer publishes them.
+// NewHTTPIssuerCAFetcher returns one backed by a plain HTTP GET; tests substitute their own to avoid a real issuer.
+type IssuerCAFetcher func() ([]byte, error)
+
+// NewHTTPIssuerCAFetcher returns an IssuerCAFetcher that GETs url and returns the response body as the bundle. A
+// nil client uses http.DefaultClient.
+func NewHTTPIssuerCAFetcher(client *http.Client, url string) IssuerCAFetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func() ([]byte, error) {
+		resp, err := client.Get(url)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching issuer CA bundle from %q: unexpected status %d", url, resp.StatusCode)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+}
+
+// issuerCAContent is a CAContentProvider that periodically refreshes its bundle from an IssuerCAFetcher, typically
+// the cluster's own OIDC issuer, for setups that want to trust that issuer's CA automatically instead of pasting it
+// into a static file. A fetch failure logs a warning and keeps serving the last good bundle rather than going
+// empty, since a transient issuer outage shouldn't make the controller stop trusting certs it already trusted.
+type issuerCAContent struct {
+	name  string
+	fetch IssuerCAFetcher
+
+	caBundle atomic.Value // holds []byte
+
+	listeners []Listener
+}
+
+// NewDynamicIssuerCAContentFromFetcher returns a CAContentProvider that calls fetch immediately, returning an error
+// if that initial fetch fails, and then again every refreshInterval until stopCh is closed. A refresh that fails
+// after the initial one only logs a warning and retains the last-good bundle. Every listener is enqueued after a
+// refresh that changes the served bundle.
+func NewDynamicIssuerCAContentFromFetcher(name string, fetch IssuerCAFetcher, refreshInterval time.Duration, stopCh <-chan struct{}, listeners ...Listener) (CAContentProvider, error) {
+	c := &issuerCAContent{
+		name:      name,
+		fetch:     fetch,
+		listeners: listeners,
+	}
+	if err := c.refresh(); err != nil {
+		return nil, fmt.Errorf("unable to fetch initial issuer CA bundle for %q: %w", name, err)
+	}
+
+	go wait.Until(func() {
+		if err := c.refresh(); err != nil {
+			klog.Warningf("failed to refresh issuer CA bundle %q, retaining last-good content: %v", name, err)
+		}
+	}, refreshInterval, stopCh)
+
+	return c, nil
+}
+
+// refresh fetches the current bundle and, if it is non-empty and differs from what's already stored, stores it and
+// enqueues every listener. It returns the fetch error, if any, unmodified, and an error of its own for an empty
+// bundle, which is not stored.
+func (c *issuerCAContent) refresh() error {
+	bundle, err := c.fetch()
+	if err != nil {
+		return err
+	}
+	if len(bundle) == 0 {
+		return fmt.Errorf("issuer CA bundle fetch for %q returned no content", c.name)
+	}
+
+	previous, _ := c.caBundle.Load().([]byte)
+	if bytes.Equal(previous, bundle) {
+		return nil
+	}
+	c.caBundle.Store(bundle)
+	for _, listener := range c.listeners {
+		listener.Enqueue()
+	}
+	return nil
+}
+
+// Name is just an identifier
+func (c *issuerCAContent) Name() string {
+	return c.name
+}
+
+// CurrentCABundleContent provides the last successfully fetched ca bundle bytes.
+func (c *issuerCAContent) CurrentCABundleContent() []byte {
+	bundle, _ := c.caBundle.Load().([]byte)
+	return bundle
+}
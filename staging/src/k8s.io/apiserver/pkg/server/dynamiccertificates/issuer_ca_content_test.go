@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingListener counts how many times Enqueue is called, for tests that need to observe a refresh happening
+// without wiring up a full DynamicServingCertificateController.
+type countingListener struct {
+	count int32
+}
+
+func (l *countingListener) Enqueue() {
+	atomic.AddInt32(&l.count, 1)
+}
+
+func TestIssuerCAContentRefreshesOnChange(t *testing.T) {
+	var mu sync.Mutex
+	bundle := []byte("bundle-v1")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write(bundle)
+	}))
+	defer server.Close()
+
+	listener := &countingListener{}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	fetch := NewHTTPIssuerCAFetcher(server.Client(), server.URL)
+	provider, err := NewDynamicIssuerCAContentFromFetcher("test-issuer", fetch, 10*time.Millisecond, stopCh, listener)
+	if err != nil {
+		t.Fatalf("unexpected error on initial fetch: %v", err)
+	}
+
+	if got := provider.CurrentCABundleContent(); !bytes.Equal(got, []byte("bundle-v1")) {
+		t.Fatalf("expected the initial bundle to be served, got %q", got)
+	}
+
+	mu.Lock()
+	bundle = []byte("bundle-v2")
+	mu.Unlock()
+
+	deadline := time.After(2 * time.Second)
+	for {
+		if bytes.Equal(provider.CurrentCABundleContent(), []byte("bundle-v2")) {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for the refreshed bundle to be served, last saw %q", provider.CurrentCABundleContent())
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	if atomic.LoadInt32(&listener.count) == 0 {
+		t.Error("expected at least one listener Enqueue call after the bundle changed")
+	}
+}
+
+func TestIssuerCAContentInitialFetchFailure(t *testing.T) {
+	fetch := func() ([]byte, error) {
+		return nil, http.ErrHandlerTimeout
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	if _, err := NewDynamicIssuerCAContentFromFetcher("test-issuer", fetch, time.Second, stopCh); err == nil {
+		t.Error("expected an error when the initial fetch fails")
+	}
+}
+
+func TestIssuerCAContentRetainsLastGoodOnFetchFailure(t *testing.T) {
+	var mu sync.Mutex
+	fail := false
+	fetch := func() ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			return nil, http.ErrHandlerTimeout
+		}
+		return []byte("good-bundle"), nil
+	}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	provider, err := NewDynamicIssuerCAContentFromFetcher("test-issuer", fetch, 10*time.Millisecond, stopCh)
+	if err != nil {
+		t.Fatalf("unexpected error on initial fetch: %v", err)
+	}
+
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+
+	if got := provider.CurrentCABundleContent(); !bytes.Equal(got, []byte("good-bundle")) {
+		t.Errorf("expected the last-good bundle to still be served after a fetch failure, got %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/events"
+)
+
+func TestSyncCertsServesMaintenanceCertWhenProvidersFail(t *testing.T) {
+	maintenanceCert := newTestServingCert(t, "maintenance.example.com")
+	recorder := events.NewFakeRecorder(10)
+
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:       tls.Config{},
+		clientCA:            NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		ServingCertProvider: NewStaticServingCertProvider("test-serving-cert", nil),
+		MaintenanceCert:     []tls.Certificate{maintenanceCert},
+		eventRecorder:       recorder,
+	}
+
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("expected RunOnce to fall back to MaintenanceCert instead of failing, got: %v", err)
+	}
+
+	tlsConfig, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tlsConfig.Certificates) != 1 || len(tlsConfig.Certificates[0].Certificate) != len(maintenanceCert.Certificate) {
+		t.Fatalf("expected the served certificate to be MaintenanceCert, got %+v", tlsConfig.Certificates)
+	}
+
+	found := false
+	close(recorder.Events)
+	var seen []string
+	for event := range recorder.Events {
+		seen = append(seen, event)
+		if strings.Contains(event, "MaintenanceCert") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected an event recording the fallback to MaintenanceCert, got %v", seen)
+	}
+}
+
+func TestSyncCertsIgnoresMaintenanceCertWhenAProviderSucceeds(t *testing.T) {
+	realCert := newTestServingCert(t, "example.com")
+	maintenanceCert := newTestServingCert(t, "maintenance.example.com")
+
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:   tls.Config{Certificates: []tls.Certificate{realCert}},
+		clientCA:        NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		MaintenanceCert: []tls.Certificate{maintenanceCert},
+	}
+
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	tlsConfig, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tlsConfig.Certificates) != 1 || string(tlsConfig.Certificates[0].Certificate[0]) != string(realCert.Certificate[0]) {
+		t.Error("expected the real serving certificate to be served, not MaintenanceCert")
+	}
+}
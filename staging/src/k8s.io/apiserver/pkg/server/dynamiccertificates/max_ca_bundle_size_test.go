@@ -0,0 +1,48 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewTLSContentRejectsOversizedCABundle(t *testing.T) {
+	oversizedCABundle := newTestCACertPEM(t, "oversized-ca")
+
+	c := &DynamicServingCertificateController{
+		clientCA:        NewStaticCAContent("test-ca", oversizedCABundle),
+		MaxCABundleSize: len(oversizedCABundle) - 1,
+	}
+	if _, err := c.newTLSContent(); err == nil {
+		t.Fatal("expected an error for a CA bundle exceeding MaxCABundleSize")
+	} else if !strings.Contains(err.Error(), "MaxCABundleSize") {
+		t.Errorf("expected the error to mention MaxCABundleSize, got: %v", err)
+	}
+}
+
+func TestNewTLSContentAllowsCABundleWithinMaxCABundleSize(t *testing.T) {
+	caBundle := newTestCACertPEM(t, "small-ca")
+
+	c := &DynamicServingCertificateController{
+		clientCA:        NewStaticCAContent("test-ca", caBundle),
+		MaxCABundleSize: len(caBundle),
+	}
+	if _, err := c.newTLSContent(); err != nil {
+		t.Errorf("unexpected error for a CA bundle within MaxCABundleSize: %v", err)
+	}
+}
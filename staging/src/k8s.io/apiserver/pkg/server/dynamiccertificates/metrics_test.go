@@ -0,0 +1,54 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSyncMetricsDistinguishNoOpFromContentChange(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{ServerName: "test"},
+		clientCA:      NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+	}
+	// RegisterMetrics is idempotent (guarded by sync.Once) and required here: a metrics.Counter measures nothing
+	// until it's registered.
+	c.RegisterMetrics()
+
+	// syncsTotal and syncsContentChangedTotal are process-wide singletons shared by every controller instance in
+	// this package, so snapshot them before driving this test's two syncCerts calls and assert the delta rather
+	// than the absolute value, which would only be correct by accident of test execution order.
+	totalBefore := testutil.ToFloat64(syncsTotal)
+	changedBefore := testutil.ToFloat64(syncsContentChangedTotal)
+
+	if err := c.syncCerts(); err != nil {
+		t.Fatalf("unexpected error on first sync: %v", err)
+	}
+	if err := c.syncCerts(); err != nil {
+		t.Fatalf("unexpected error on second, no-op sync: %v", err)
+	}
+
+	if got := testutil.ToFloat64(syncsTotal) - totalBefore; got != 2 {
+		t.Errorf("expected 2 more syncs recorded, got %v", got)
+	}
+	if got := testutil.ToFloat64(syncsContentChangedTotal) - changedBefore; got != 1 {
+		t.Errorf("expected 1 more content-changed sync recorded, got %v", got)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestGetConfigForClientErrorsBeforeFirstSyncByDefault(t *testing.T) {
+	c := &DynamicServingCertificateController{}
+
+	if _, err := c.GetConfigForClient(&tls.ClientHelloInfo{}); err == nil {
+		t.Fatal("expected an error before the first sync")
+	}
+	if _, err := c.GetConfigForClientNoClone(&tls.ClientHelloInfo{}); err == nil {
+		t.Fatal("expected an error before the first sync")
+	}
+}
+
+func TestGetConfigForClientServesNotReadyCertBeforeFirstSync(t *testing.T) {
+	notReadyCert := newTestServingCert(t, "not-ready.example.com")
+	c := &DynamicServingCertificateController{
+		NotReadyCert: []tls.Certificate{notReadyCert},
+	}
+
+	served, err := c.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(served.Certificates) != 1 || served.Certificates[0].Leaf != notReadyCert.Leaf {
+		t.Errorf("expected NotReadyCert to be served, got %+v", served.Certificates)
+	}
+
+	servedNoClone, err := c.GetConfigForClientNoClone(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(servedNoClone.Certificates) != 1 || servedNoClone.Certificates[0].Leaf != notReadyCert.Leaf {
+		t.Errorf("expected NotReadyCert to be served, got %+v", servedNoClone.Certificates)
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"bytes"
+)
+
+// OCSPResponseProvider provides a DER-encoded OCSP response to staple onto every certificate this controller serves,
+// so clients don't need a separate round trip to the OCSP responder.
+type OCSPResponseProvider interface {
+	// Name is just an identifier
+	Name() string
+	// CurrentOCSPResponse returns the current DER-encoded OCSP response, or nil if none is available yet. As with
+	// CAContentProvider, errors are contained to the controller that initializes the value.
+	CurrentOCSPResponse() []byte
+}
+
+// ocspStapleContent holds the OCSP staple content that overrides baseTLSConfig.Certificates' OCSPStaple field.
+// Wrapping the bytes makes Equal work nicely with the method receiver, consistent with caBundleContent.
+type ocspStapleContent struct {
+	staple []byte
+}
+
+func (c *ocspStapleContent) Equal(rhs *ocspStapleContent) bool {
+	if c == nil || rhs == nil {
+		return c == rhs
+	}
+	return bytes.Equal(c.staple, rhs.staple)
+}
+
+type staticOCSPResponseProvider struct {
+	name     string
+	response []byte
+}
+
+// NewStaticOCSPResponseProvider returns an OCSPResponseProvider that always returns the same DER-encoded response.
+func NewStaticOCSPResponseProvider(name string, response []byte) OCSPResponseProvider {
+	return &staticOCSPResponseProvider{name: name, response: response}
+}
+
+// Name is just an identifier
+func (p *staticOCSPResponseProvider) Name() string {
+	return p.name
+}
+
+// CurrentOCSPResponse returns the static DER-encoded OCSP response.
+func (p *staticOCSPResponseProvider) CurrentOCSPResponse() []byte {
+	return p.response
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// PublishTarget names a ConfigMap key that should mirror the served client CA bundle, for clients that need to pull
+// the current trust bundle from the API rather than share this controller's own CAContentProvider(s) directly.
+type PublishTarget struct {
+	// Client is used to read and write the ConfigMap.
+	Client kubernetes.Interface
+	// Namespace is the ConfigMap's namespace.
+	Namespace string
+	// Name is the ConfigMap's name. It's created if it doesn't already exist.
+	Name string
+	// Key is the data key within the ConfigMap that's set to the served client CA bundle's PEM bytes.
+	Key string
+}
+
+// publishCABundle writes caBundle to c.PublishTarget's ConfigMap key, creating the ConfigMap if it doesn't already
+// exist. A no-op if PublishTarget isn't set. Update conflicts (another writer touched the ConfigMap between our Get
+// and Update) are retried with retry.RetryOnConflict, re-reading and re-applying the write each attempt.
+func (c *DynamicServingCertificateController) publishCABundle(caBundle []byte) error {
+	target := c.PublishTarget
+	if target == nil {
+		return nil
+	}
+
+	configMaps := target.Client.CoreV1().ConfigMaps(target.Namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := configMaps.Get(target.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err := configMaps.Create(&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: target.Name, Namespace: target.Namespace},
+				Data:       map[string]string{target.Key: string(caBundle)},
+			})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		updated := existing.DeepCopy()
+		if updated.Data == nil {
+			updated.Data = map[string]string{}
+		}
+		if updated.Data[target.Key] == string(caBundle) {
+			return nil
+		}
+		updated.Data[target.Key] = string(caBundle)
+		_, err = configMaps.Update(updated)
+		return err
+	})
+}
+
+// publishTargetFailureMessage formats the warning/event message for a failed publishCABundle call.
+func publishTargetFailureMessage(target *PublishTarget, err error) string {
+	return fmt.Sprintf("failed to publish client CA bundle to ConfigMap %s/%s: %v", target.Namespace, target.Name, err)
+}
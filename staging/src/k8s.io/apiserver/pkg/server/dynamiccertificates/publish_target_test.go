@@ -0,0 +1,69 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSyncCertsPublishesCABundleToConfigMap(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	target := &PublishTarget{Client: client, Namespace: "kube-system", Name: "trust-bundle", Key: "ca.crt"}
+
+	firstBundle := newTestCACertPEM(t, "first")
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:      NewStaticCAContent("test-ca", firstBundle),
+		PublishTarget: target,
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error on first sync: %v", err)
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps("kube-system").Get("trust-bundle", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the ConfigMap to be created on first sync: %v", err)
+	}
+	if configMap.Data["ca.crt"] != string(firstBundle) {
+		t.Errorf("expected the published bundle to match the served one on first sync")
+	}
+
+	secondBundle := newTestCACertPEM(t, "second")
+	c.clientCA = NewStaticCAContent("test-ca", secondBundle)
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error on second sync: %v", err)
+	}
+
+	configMap, err = client.CoreV1().ConfigMaps("kube-system").Get("trust-bundle", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configMap.Data["ca.crt"] != string(secondBundle) {
+		t.Error("expected the published bundle to reflect the rotated client CA content")
+	}
+}
+
+func TestPublishCABundleNoOpWithoutTarget(t *testing.T) {
+	c := &DynamicServingCertificateController{}
+	if err := c.publishCABundle([]byte("irrelevant")); err != nil {
+		t.Errorf("expected publishCABundle to no-op without a PublishTarget, got: %v", err)
+	}
+}
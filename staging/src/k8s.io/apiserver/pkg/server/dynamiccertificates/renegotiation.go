@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+)
+
+// RenegotiationProvider supplies the tls.RenegotiationSupport policy syncCerts installs on the served tls.Config,
+// for deployments that need to change their renegotiation stance (e.g. tls.RenegotiateOnceAsClient for legacy
+// clients that require it, or tls.RenegotiateNever for ones that must have it disabled) without restarting the
+// server.
+type RenegotiationProvider interface {
+	// Name is just an identifier
+	Name() string
+	// CurrentRenegotiationSupport returns the renegotiation policy that should currently be in effect.
+	CurrentRenegotiationSupport() tls.RenegotiationSupport
+}
+
+// renegotiationContent holds the renegotiation policy overriding baseTLSConfig's default.
+type renegotiationContent struct {
+	support tls.RenegotiationSupport
+}
+
+func (c *renegotiationContent) Equal(rhs *renegotiationContent) bool {
+	if c == nil || rhs == nil {
+		return c == rhs
+	}
+	return c.support == rhs.support
+}
+
+type staticRenegotiationProvider struct {
+	name    string
+	support tls.RenegotiationSupport
+}
+
+// NewStaticRenegotiationProvider returns a RenegotiationProvider that always returns the same renegotiation policy.
+func NewStaticRenegotiationProvider(name string, support tls.RenegotiationSupport) RenegotiationProvider {
+	return &staticRenegotiationProvider{name: name, support: support}
+}
+
+// Name is just an identifier
+func (p *staticRenegotiationProvider) Name() string {
+	return p.name
+}
+
+// CurrentRenegotiationSupport returns the static renegotiation policy.
+func (p *staticRenegotiationProvider) CurrentRenegotiationSupport() tls.RenegotiationSupport {
+	return p.support
+}
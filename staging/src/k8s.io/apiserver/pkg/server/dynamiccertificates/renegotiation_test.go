@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestSyncCertsReflectsUpdatedRenegotiationPolicy(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:         tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:              NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		RenegotiationProvider: NewStaticRenegotiationProvider("test-renegotiation", tls.RenegotiateOnceAsClient),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tlsConfig, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.Renegotiation != tls.RenegotiateOnceAsClient {
+		t.Fatalf("expected the served config's Renegotiation to reflect the provider's policy, got %v", tlsConfig.Renegotiation)
+	}
+
+	c.RenegotiationProvider = NewStaticRenegotiationProvider("test-renegotiation", tls.RenegotiateFreelyAsClient)
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error on second sync: %v", err)
+	}
+
+	tlsConfig, err = c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.Renegotiation != tls.RenegotiateFreelyAsClient {
+		t.Errorf("expected the served config's Renegotiation to reflect the updated policy, got %v", tlsConfig.Renegotiation)
+	}
+}
+
+func TestRenegotiationDefaultsToNeverWithoutProvider(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:      NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tlsConfig, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.Renegotiation != tls.RenegotiateNever {
+		t.Errorf("expected Renegotiation to default to tls.RenegotiateNever without a RenegotiationProvider, got %v", tlsConfig.Renegotiation)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// ProviderSelfTestResult reports the outcome of loading a single content provider during SelfTest.
+type ProviderSelfTestResult struct {
+	// Name identifies the provider, as returned by its Name() method.
+	Name string
+	// Error is nil if the provider's current content loaded successfully, or the error encountered otherwise.
+	Error error
+}
+
+// SelfTestResult is the outcome of a SelfTest run: whether each configured provider could be loaded, whether the
+// resulting content could be assembled into a working TLS config, and any non-fatal validation warnings (e.g. a
+// client CA that fails CertPolicy but isn't required to pass it) surfaced along the way.
+type SelfTestResult struct {
+	// Providers holds one entry per configured content provider, in the order they were loaded. ServingCertProvider
+	// is listed only when baseTLSConfig carries no certificates of its own.
+	Providers []ProviderSelfTestResult
+	// Warnings holds non-fatal issues found while validating loaded content, such as a client CA failing CertPolicy
+	// when RequireCertPolicy is false.
+	Warnings []string
+	// TLSConfigError is nil if a *tls.Config assembled from the loaded content passed a local self-handshake, or the
+	// error encountered otherwise (including a serving certificate that never became available).
+	TLSConfigError error
+}
+
+// AnyProviderFailed reports whether any provider in Providers failed to load.
+func (r *SelfTestResult) AnyProviderFailed() bool {
+	for _, p := range r.Providers {
+		if p.Error != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// SelfTest loads every configured content provider once, attempts to assemble and self-verify a *tls.Config from the
+// result exactly as syncCerts would, and reports a structured result -- without storing anything as this
+// controller's currently served content. It's meant for ops verification (e.g. a --dry-run flag or an admin
+// endpoint) that wants to validate a controller's configuration is loadable without affecting what it's actually
+// serving.
+func (c *DynamicServingCertificateController) SelfTest(ctx context.Context) (*SelfTestResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := &SelfTestResult{}
+
+	effectiveClientCA := c.effectiveClientCA()
+	clientCABundle := effectiveClientCA.CurrentCABundleContent()
+	clientCAErr := c.checkClientCABundle(clientCABundle, &result.Warnings)
+	result.Providers = append(result.Providers, ProviderSelfTestResult{Name: effectiveClientCA.Name(), Error: clientCAErr})
+
+	if c.OCSPResponseProvider != nil {
+		result.Providers = append(result.Providers, ProviderSelfTestResult{Name: c.OCSPResponseProvider.Name()})
+	}
+	if c.SessionTicketKeyProvider != nil {
+		result.Providers = append(result.Providers, ProviderSelfTestResult{Name: c.SessionTicketKeyProvider.Name()})
+	}
+	if c.IntermediateProvider != nil {
+		result.Providers = append(result.Providers, ProviderSelfTestResult{Name: c.IntermediateProvider.Name()})
+	}
+	if c.RenegotiationProvider != nil {
+		result.Providers = append(result.Providers, ProviderSelfTestResult{Name: c.RenegotiationProvider.Name()})
+	}
+
+	tlsConfig := c.baseTLSConfig.Clone()
+	if clientCAErr == nil {
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(clientCABundle)
+		tlsConfig.ClientCAs = pool
+	}
+	if len(tlsConfig.Certificates) == 0 && c.ServingCertProvider != nil {
+		servingCert := c.ServingCertProvider.CurrentServingCertificate()
+		result.Providers = append(result.Providers, ProviderSelfTestResult{Name: c.ServingCertProvider.Name(), Error: servingCertError(servingCert)})
+		tlsConfig.Certificates = servingCert
+	}
+	if len(tlsConfig.Certificates) == 0 && len(c.MaintenanceCert) > 0 {
+		tlsConfig.Certificates = c.MaintenanceCert
+	}
+
+	result.TLSConfigError = c.validateTLSConfig(tlsConfig)
+	return result, nil
+}
+
+// checkClientCABundle decodes bundle exactly as syncCerts would and appends a warning for every cert that fails
+// CertPolicy, mirroring syncCerts' non-required behavior regardless of RequireCertPolicy: SelfTest reports every
+// issue it finds as a warning rather than failing outright on one, since its purpose is to surface problems, not to
+// enforce them.
+func (c *DynamicServingCertificateController) checkClientCABundle(bundle []byte, warnings *[]string) error {
+	if len(bundle) == 0 {
+		return fmt.Errorf("client CA bundle is currently empty")
+	}
+	decoder := c.ClientCABundleDecoder
+	if decoder == nil {
+		decoder = PEMBundleDecoder
+	}
+	certs, err := decoder(bundle)
+	if err != nil {
+		return fmt.Errorf("unable to load client CA bundle: %v", err)
+	}
+	if c.CertPolicy != nil {
+		for i, cert := range certs {
+			if err := c.CertPolicy.Validate(cert); err != nil {
+				*warnings = append(*warnings, fmt.Sprintf("client CA [%d/%q] failed certificate policy: %v", i, cert.Subject, err))
+			}
+		}
+	}
+	return nil
+}
+
+// servingCertError returns an error if servingCert is empty, since SelfTest treats "no serving certificate loaded"
+// as the provider's own failure rather than deferring to MaintenanceCert the way syncCerts does.
+func servingCertError(servingCert []tls.Certificate) error {
+	if len(servingCert) == 0 {
+		return fmt.Errorf("no serving certificate currently available")
+	}
+	return nil
+}
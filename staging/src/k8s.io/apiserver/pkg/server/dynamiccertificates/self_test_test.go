@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+)
+
+func TestSelfTestReportsBrokenClientCAProvider(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{ServerName: "test"},
+		clientCA:      NewStaticCAContent("broken-ca", nil),
+	}
+
+	result, err := c.SelfTest(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.AnyProviderFailed() {
+		t.Fatal("expected SelfTest to report the empty client CA provider as failed")
+	}
+	if result.Providers[0].Name != "broken-ca" || result.Providers[0].Error == nil {
+		t.Errorf("expected a failed result for provider %q, got %+v", "broken-ca", result.Providers)
+	}
+}
+
+func TestSelfTestSucceedsForWorkingConfiguration(t *testing.T) {
+	servingCert := newTestServingCert(t, "example.com")
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:       tls.Config{ServerName: "example.com"},
+		clientCA:            NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		ServingCertProvider: NewStaticServingCertProvider("test-serving-cert", []tls.Certificate{servingCert}),
+	}
+
+	result, err := c.SelfTest(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.AnyProviderFailed() {
+		t.Fatalf("expected every provider to succeed, got %+v", result.Providers)
+	}
+	if result.TLSConfigError != nil {
+		t.Errorf("expected the assembled TLS config to self-verify, got: %v", result.TLSConfigError)
+	}
+}
+
+func TestSelfTestWarnsOnCertPolicyViolationWithoutFailing(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{ServerName: "test"},
+		clientCA:      NewStaticCAContent("weak-ca", newTestCACertPEMWithKeySize(t, "weak-ca", 1024)),
+		CertPolicy:    MinKeySizePolicy{MinBits: 2048},
+	}
+
+	result, err := c.SelfTest(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.AnyProviderFailed() {
+		t.Fatalf("expected CertPolicy violations to be reported as warnings, not provider failures: %+v", result.Providers)
+	}
+	if len(result.Warnings) == 0 {
+		t.Error("expected a warning about the client CA failing CertPolicy")
+	}
+}
+
+func TestSelfTestDoesNotAffectCurrentlyServedContent(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{ServerName: "test"},
+		clientCA:      NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+	}
+
+	if _, err := c.SelfTest(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.currentlyServedContent != nil {
+		t.Error("expected SelfTest not to update currentlyServedContent")
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newTestServingCertWithoutServerAuthEKU returns a certificate otherwise identical to newTestServingCert's output,
+// except its ExtKeyUsage omits x509.ExtKeyUsageServerAuth, for exercising CheckServerAuthEKU/RequireServerAuthEKU.
+func newTestServingCertWithoutServerAuthEKU(t *testing.T, dnsNames ...string) tls.Certificate {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "serving"},
+		DNSNames:              dnsNames,
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestCertsWithoutServerAuthEKU(t *testing.T) {
+	withoutServerAuth := newTestServingCertWithoutServerAuthEKU(t, "no-server-auth.example.com")
+	if got := certsWithoutServerAuthEKU([]tls.Certificate{withoutServerAuth}); len(got) != 1 {
+		t.Fatalf("expected the cert lacking serverAuth EKU to be reported, got %v", got)
+	}
+
+	withServerAuth := newTestServingCert(t, "has-server-auth.example.com")
+	if got := certsWithoutServerAuthEKU([]tls.Certificate{withServerAuth}); len(got) != 0 {
+		t.Fatalf("expected a cert with serverAuth EKU not to be reported, got %v", got)
+	}
+}
+
+func TestSyncCertsCheckServerAuthEKU(t *testing.T) {
+	newController := func(requireServerAuth bool) *DynamicServingCertificateController {
+		return &DynamicServingCertificateController{
+			baseTLSConfig:        tls.Config{Certificates: []tls.Certificate{newTestServingCertWithoutServerAuthEKU(t, "no-server-auth.example.com")}},
+			clientCA:             NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+			CheckServerAuthEKU:   true,
+			RequireServerAuthEKU: requireServerAuth,
+		}
+	}
+
+	if err := newController(false).RunOnce(); err != nil {
+		t.Errorf("expected a missing serverAuth EKU to only warn by default, got error: %v", err)
+	}
+	if err := newController(true).RunOnce(); err == nil {
+		t.Error("expected RunOnce to fail once RequireServerAuthEKU is set and the cert still lacks the serverAuth EKU")
+	}
+}
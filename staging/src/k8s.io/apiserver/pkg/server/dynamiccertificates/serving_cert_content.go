@@ -0,0 +1,50 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"bytes"
+	"crypto/tls"
+)
+
+// servingCertContent holds the raw bytes of the serving certificate(s) that would be installed on the served
+// tls.Config, purely for content-change detection: unlike caBundleContent, this doesn't get parsed, it's just
+// compared. Wrapping the slice makes the Equal work nicely with the method receiver, matching caBundleContent.
+type servingCertContent struct {
+	certs []tls.Certificate
+}
+
+// Equal reports whether c and rhs hold byte-identical certificate chains, in the same order.
+func (c *servingCertContent) Equal(rhs *servingCertContent) bool {
+	if c == nil || rhs == nil {
+		return c == rhs
+	}
+	if len(c.certs) != len(rhs.certs) {
+		return false
+	}
+	for i := range c.certs {
+		if len(c.certs[i].Certificate) != len(rhs.certs[i].Certificate) {
+			return false
+		}
+		for j := range c.certs[i].Certificate {
+			if !bytes.Equal(c.certs[i].Certificate[j], rhs.certs[i].Certificate[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
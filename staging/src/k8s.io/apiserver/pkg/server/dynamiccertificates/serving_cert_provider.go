@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+)
+
+// ServingCertProvider supplies the serving certificate(s) syncCerts installs on the served tls.Config when
+// baseTLSConfig doesn't carry any of its own, for setups (e.g. cert-manager-style dynamic issuance) that manage the
+// serving certificate independently of the static config passed to NewDynamicServingCertificateController. It's only
+// consulted when baseTLSConfig.Certificates is empty; a non-empty baseTLSConfig.Certificates always wins.
+type ServingCertProvider interface {
+	// Name is just an identifier
+	Name() string
+	// CurrentServingCertificate returns the current serving certificate(s), or nil if none is available yet. As with
+	// CAContentProvider, errors are contained to the controller that initializes the value.
+	CurrentServingCertificate() []tls.Certificate
+}
+
+type staticServingCertProvider struct {
+	name  string
+	certs []tls.Certificate
+}
+
+// NewStaticServingCertProvider returns a ServingCertProvider that always returns the same serving certificate(s).
+func NewStaticServingCertProvider(name string, certs []tls.Certificate) ServingCertProvider {
+	return &staticServingCertProvider{name: name, certs: certs}
+}
+
+// Name is just an identifier
+func (p *staticServingCertProvider) Name() string {
+	return p.name
+}
+
+// CurrentServingCertificate returns the static serving certificate(s).
+func (p *staticServingCertProvider) CurrentServingCertificate() []tls.Certificate {
+	return p.certs
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"bytes"
+)
+
+// SessionTicketKeyProvider provides the session ticket keys syncCerts installs on every served tls.Config via
+// SetSessionTicketKeys, so ticket-based resumption keys can rotate on their own schedule, typically tied to the
+// certificate/CA rotation cycle, instead of the single long-lived key crypto/tls otherwise generates for itself.
+type SessionTicketKeyProvider interface {
+	// Name is just an identifier
+	Name() string
+	// CurrentSessionTicketKeys returns the current set of session ticket keys, most preferred first, in the order
+	// crypto/tls.Config.SetSessionTicketKeys expects. A nil or empty return leaves crypto/tls's own randomly
+	// generated default key in place.
+	CurrentSessionTicketKeys() [][32]byte
+}
+
+// sessionTicketKeysContent holds the session ticket keys overriding baseTLSConfig's default. Wrapping the slice
+// makes Equal work nicely with the method receiver, consistent with caBundleContent and ocspStapleContent.
+type sessionTicketKeysContent struct {
+	keys [][32]byte
+}
+
+func (c *sessionTicketKeysContent) Equal(rhs *sessionTicketKeysContent) bool {
+	if c == nil || rhs == nil {
+		return c == rhs
+	}
+	if len(c.keys) != len(rhs.keys) {
+		return false
+	}
+	for i := range c.keys {
+		if !bytes.Equal(c.keys[i][:], rhs.keys[i][:]) {
+			return false
+		}
+	}
+	return true
+}
+
+type staticSessionTicketKeyProvider struct {
+	name string
+	keys [][32]byte
+}
+
+// NewStaticSessionTicketKeyProvider returns a SessionTicketKeyProvider that always returns the same set of keys.
+func NewStaticSessionTicketKeyProvider(name string, keys [][32]byte) SessionTicketKeyProvider {
+	return &staticSessionTicketKeyProvider{name: name, keys: keys}
+}
+
+// Name is just an identifier
+func (p *staticSessionTicketKeyProvider) Name() string {
+	return p.name
+}
+
+// CurrentSessionTicketKeys returns the static set of session ticket keys.
+func (p *staticSessionTicketKeyProvider) CurrentSessionTicketKeys() [][32]byte {
+	return p.keys
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type recordingEventRecorder struct {
+	eventCount int
+}
+
+func (r *recordingEventRecorder) Eventf(regarding, related runtime.Object, eventtype, reason, action, note string, args ...interface{}) {
+	r.eventCount++
+}
+
+func TestSetEventRecorderSwapsTargetForSubsequentEvents(t *testing.T) {
+	oldRecorder := &recordingEventRecorder{}
+	newRecorder := &recordingEventRecorder{}
+
+	c := &DynamicServingCertificateController{eventRecorder: oldRecorder}
+	c.emitEvent("Normal", "Test", "Test", "before handoff")
+	if oldRecorder.eventCount != 1 {
+		t.Fatalf("expected the old recorder to see 1 event, got %d", oldRecorder.eventCount)
+	}
+
+	c.SetEventRecorder(newRecorder)
+	c.emitEvent("Normal", "Test", "Test", "after handoff")
+
+	if oldRecorder.eventCount != 1 {
+		t.Errorf("expected the old recorder to still see only 1 event after handoff, got %d", oldRecorder.eventCount)
+	}
+	if newRecorder.eventCount != 1 {
+		t.Errorf("expected the new recorder to see 1 event after handoff, got %d", newRecorder.eventCount)
+	}
+}
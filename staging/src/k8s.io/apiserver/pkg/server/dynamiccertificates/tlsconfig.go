@@ -17,10 +17,15 @@ limitations under the License.
 package dynamiccertificates
 
 import (
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/asn1"
 	"errors"
 	"fmt"
+	"net"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -29,31 +34,252 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/events"
-	"k8s.io/client-go/util/cert"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 )
 
 const workItemKey = "key"
 
+// ErrEmptyCABundle is returned (wrapped) by newTLSContent when the configured CAContentProvider currently has no
+// content to serve. Callers can use errors.Is to distinguish this transient condition from other sync failures, for
+// example to avoid counting it against the workqueue's retry budget.
+var ErrEmptyCABundle = errors.New("not loading an empty client ca bundle")
+
 // DynamicServingCertificateController dynamically loads certificates and provides a golang tls compatible dynamic GetCertificate func.
 type DynamicServingCertificateController struct {
+	// mu guards baseTLSConfig, clientCA, ServingCertProvider, additionalCAProviders, currentlyServedContent,
+	// currentClientCACerts, lastContentChangeTime, emptyCABundleSince, and contentHistory: everything syncCerts and
+	// its callers (AddCAProvider, RemoveCAProvider, ReconfigureAll) read or write outside of
+	// currentServingTLSConfig's own atomic storage. syncCerts holds the write lock for its full duration; every
+	// other accessor takes a read lock, except the handful that briefly hold the write lock themselves to mutate the
+	// provider set or baseTLSConfig/clientCA/ServingCertProvider before triggering a resync. This lets more than one
+	// worker safely drive syncCerts, and lets a reconfiguration call race safely with a queue-driven sync, instead of
+	// relying on there only ever being one goroutine involved.
+	mu sync.RWMutex
+
 	// baseTLSConfig is the static portion of the tlsConfig for serving to clients.  It is copied and the copy is mutated
 	// based on the dynamic cert state.
 	baseTLSConfig tls.Config
 
 	// clientCA provides the very latest content of the ca bundle
 	clientCA CAContentProvider
+	// additionalCAProviders holds CA providers registered via AddCAProvider, keyed by name, that are unioned with
+	// clientCA when building the served client CA pool. Removed via RemoveCAProvider.
+	additionalCAProviders map[string]CAContentProvider
 
 	// currentlyServedContent holds the original bytes that we are serving. This is used to decide if we need to set a
 	// new atomic value. The types used for efficient TLSConfig preclude using the processed value.
 	currentlyServedContent *dynamicCertificateContent
 	// currentServingTLSConfig holds a *tls.Config that will be used to serve requests
 	currentServingTLSConfig atomic.Value
+	// currentClientCACerts holds the parsed, post-filtering certs backing currentServingTLSConfig's ClientCAs pool.
+	// This lets CurrentClientCAPool build a fresh pool without reparsing PEM or trusting certs FilterNonCACerts
+	// skipped.
+	currentClientCACerts []*x509.Certificate
 
 	// queue only ever has one item, but it has nice error handling backoff/retry semantics
 	queue         workqueue.RateLimitingInterface
 	eventRecorder events.EventRecorder
+	// eventRecorderMutex guards eventRecorder, so SetEventRecorder can swap it (e.g. after a leader election
+	// handoff changes the involved object) while emitEvent is reading it concurrently from a running sync.
+	eventRecorderMutex sync.RWMutex
+
+	// shuttingDown is set by Shutdown to make Enqueue a no-op once a clean shutdown has begun.
+	shuttingDown int32
+
+	// pendingMu guards pending. It's set by Enqueue when a sync is queued and, once that sync succeeds,
+	// recomputed by processNextWorkItem from queue.Len() -- checked only after queue.Done(), so a concurrent
+	// Enqueue() that re-dirtied the item while the sync was in flight is reflected -- rather than cleared
+	// unconditionally, so Shutdown never mistakes a freshly re-queued sync for a drained queue. queue.Len() alone
+	// isn't a substitute for pending: the workqueue's own doc warns it isn't safe to gate decisions on, and it
+	// reads 0 for an item queue.Get() has already dequeued but processNextWorkItem hasn't started yet.
+	pendingMu sync.Mutex
+	pending   bool
+
+	// FilterNonCACerts, when true, skips certificates whose BasicConstraints mark them as non-CA (IsCA == false)
+	// when building the ClientCAs pool, so a bundle pasted as a full chain (leaf + intermediates + root) doesn't
+	// accidentally trust the leaf. Defaults to false to preserve existing behavior of trusting every cert in the
+	// bundle.
+	FilterNonCACerts bool
+
+	// ClientCABundleChangeHandler, if set, is called by syncCerts whenever a client CA bundle rotation adds or
+	// removes at least one certificate, with the added and removed certs computed by diffing the previous and new
+	// parsed pools by subject and serial number. Runs after the same diff already used to emit the
+	// ClientCABundleChanged event, so a caller wanting more than the event's summary (e.g. paging on an unexpected
+	// removal) doesn't have to reparse the bundles itself. Nil, the default, skips calling it.
+	ClientCABundleChangeHandler func(added, removed []*x509.Certificate)
+
+	// MaxClientChainDepth, if greater than zero, rejects a client certificate whose verified chain (root through
+	// leaf, inclusive) is longer than this many certificates. This composes with the normal ClientCAs verification
+	// done by crypto/tls: it only runs once a chain has already been built and trusted, and exists to defend against
+	// pathologically long chains rather than untrusted ones.
+	MaxClientChainDepth int
+
+	// RequiredIssuerSubjects, if non-empty, rejects a client certificate unless its verified chain includes a
+	// certificate whose Subject matches one of these (via x509.Name.String()). Like MaxClientChainDepth, this only
+	// runs once a chain has already been built and trusted against ClientCAs; it narrows "trusted by the root CA" to
+	// "trusted by a specific intermediate" for deployments where multiple intermediates share the same root.
+	RequiredIssuerSubjects []string
+
+	// nowFn returns the current time and is overridden by tests. Defaults to time.Now.
+	nowFn func() time.Time
+	// lastContentChangeTime records when currentlyServedContent last actually changed, as opposed to the last time
+	// syncCerts ran. A rotation that gets stuck shows up as this age growing without bound.
+	lastContentChangeTime time.Time
+
+	// ExpectedServerNames, if set, is checked against baseTLSConfig.Certificates on every sync: each name must be
+	// covered (per x509.Certificate.VerifyHostname, so wildcards work) by at least one configured serving
+	// certificate. This catches a misissued or stale cert at rotation time instead of at the first client handshake
+	// that needs the missing name.
+	ExpectedServerNames []string
+	// RequireServerNamesMatch, when true, makes syncCerts fail (and therefore keep serving the last good config)
+	// if ExpectedServerNames isn't fully covered. When false, the default, a mismatch only logs a warning and emits
+	// an event, and the new certificate is still served.
+	RequireServerNamesMatch bool
+
+	// CheckSCTPresence, when true, has syncCerts check that every configured serving certificate embeds a
+	// Certificate Transparency SignedCertificateTimestampList extension, for compliance regimes that require it on
+	// public-facing certs. This is advisory and off by default.
+	CheckSCTPresence bool
+	// RequireSCTPresence, when true (and CheckSCTPresence is true), makes syncCerts fail if a certificate lacks
+	// embedded SCTs. When false, the default, a missing SCT only logs a warning and emits an event.
+	RequireSCTPresence bool
+
+	// CheckServerAuthEKU, when true, has syncCerts check that every configured serving certificate's
+	// ExtKeyUsage includes x509.ExtKeyUsageServerAuth, catching the common misconfiguration of a serving cert
+	// issued for some other purpose (client auth, code signing) that TLS clients will reject anyway. This is
+	// advisory and off by default.
+	CheckServerAuthEKU bool
+	// RequireServerAuthEKU, when true (and CheckServerAuthEKU is true), makes syncCerts fail if a certificate
+	// lacks the serverAuth EKU. When false, the default, a missing serverAuth EKU only logs a warning and emits
+	// an event.
+	RequireServerAuthEKU bool
+
+	// ClientCABundleDecoder decodes the effective client CA bundle bytes into certificates. Defaults to
+	// PEMBundleDecoder; set to PKCS7BundleDecoder to accept a DER-encoded PKCS#7 bundle instead.
+	ClientCABundleDecoder BundleDecoder
+
+	// MaxCABundleSize, if greater than zero, has newTLSContent reject a client CA bundle larger than this many bytes
+	// before it's ever handed to ClientCABundleDecoder, so a maliciously or accidentally huge bundle fails fast with
+	// a clear error instead of burning memory and CPU parsing it. Zero, the default, applies no limit.
+	MaxCABundleSize int
+
+	// OCSPResponseProvider, if set, has syncCerts staple its current OCSP response onto every certificate in
+	// baseTLSConfig.Certificates, refreshing it whenever the response rotates. Nil, the default, leaves OCSPStaple
+	// unset.
+	OCSPResponseProvider OCSPResponseProvider
+
+	// ServingCertProvider, if set, supplies the serving certificate(s) syncCerts installs when
+	// baseTLSConfig.Certificates is empty, for setups where the serving cert is managed independently of the static
+	// config passed to NewDynamicServingCertificateController or ReconfigureAll. A non-empty
+	// baseTLSConfig.Certificates always wins over it. Setting this field opts into requiring a usable serving
+	// certificate: if baseTLSConfig.Certificates is empty and the provider also currently has nothing, the first sync
+	// fails clearly instead of silently serving with no certificate. Nil, the default, leaves the long-standing
+	// behavior of tolerating an empty baseTLSConfig.Certificates unchanged, for controllers only used to manage
+	// ClientCAs.
+	ServingCertProvider ServingCertProvider
+
+	// IntermediateProvider, if set, has syncCerts append its current intermediate certificate(s) to every serving
+	// certificate's chain, for a leaf that's issued without its intermediates bundled in. Nil, the default, serves
+	// each certificate's chain exactly as supplied.
+	IntermediateProvider IntermediateProvider
+
+	// MaintenanceCert, if set, is served in place of failing handshakes altogether when baseTLSConfig and
+	// ServingCertProvider both currently supply nothing: a clearly-labeled fallback certificate (e.g. one whose
+	// CommonName says so) lets clients still connect, and lets a human notice the degraded state from a client-side
+	// certificate warning, rather than every connection simply failing until the provider recovers. Only consulted
+	// once ServingCertProvider has already been tried and come up empty, so it composes with the field above
+	// exactly as documented there. Nil, the default, leaves that case as described under ServingCertProvider: a first
+	// sync fails, while a later one goes ahead and serves a config with no certificate.
+	MaintenanceCert []tls.Certificate
+
+	// SessionTicketKeyProvider, if set, has syncCerts install its current keys onto the served tls.Config via
+	// SetSessionTicketKeys, refreshing them whenever they rotate, e.g. in step with the certificate rotation cycle.
+	// A rotation is treated like any other content change: it swaps in a newly served config. Nil, the default, or
+	// a provider currently returning no keys, leaves crypto/tls's own randomly generated default key in place.
+	SessionTicketKeyProvider SessionTicketKeyProvider
+
+	// PublishTarget, if set, has syncCerts mirror the served client CA bundle to a ConfigMap after every successful
+	// swap, for clients that pull the current trust bundle from the API rather than share this controller's own
+	// CAContentProvider(s) directly. A publish failure only logs a warning and emits an event; the newly synced
+	// content is still served either way. Nil, the default, publishes nothing.
+	PublishTarget *PublishTarget
+
+	// RenegotiationProvider, if set, has syncCerts install its current renegotiation policy onto the served
+	// tls.Config's Renegotiation field, refreshing it whenever the policy changes, so a policy update is treated
+	// like any other content change: it swaps in a newly served config. Nil, the default, leaves Renegotiation at
+	// tls.RenegotiateNever, crypto/tls's own zero-value default.
+	RenegotiationProvider RenegotiationProvider
+
+	// MaxContentHistory, if greater than zero, has syncCerts record each content change in contentHistory (retrieved
+	// via ContentHistory), for auditors who need a record of every CA/serving cert rotation. Zero, the default,
+	// disables recording entirely.
+	MaxContentHistory int
+	// contentHistory is the bounded ring buffer MaxContentHistory records into; oldest entries are dropped once it's
+	// full.
+	contentHistory []ContentChange
+
+	// listenerClientCAs maps a listener's local address (net.Conn.LocalAddr().String(), as seen on the connection a
+	// ClientHelloInfo was generated from) to a CAContentProvider GetConfigForClient uses instead of the default
+	// client CA for connections accepted on that listener. Populated via RegisterListenerClientCA; nil, the
+	// default, means every listener shares the same client CA.
+	listenerClientCAs      map[string]CAContentProvider
+	listenerClientCAsMutex sync.RWMutex
+
+	// EmptyCABundleGracePeriod, if positive, has newTLSContent tolerate the configured client CA reporting an empty
+	// bundle for up to this long, retaining the last successfully loaded client CA content instead of failing the
+	// sync, on the theory that an informer resync can momentarily surface an empty bundle during a resource
+	// transition rather than the CA genuinely having disappeared. Once the grace period elapses with the bundle
+	// still empty, the sync fails as it always has. Zero, the default, fails a sync the moment the bundle is empty.
+	EmptyCABundleGracePeriod time.Duration
+	// emptyCABundleSince records when the client CA bundle was first observed empty since it was last seen non-empty,
+	// so EmptyCABundleGracePeriod can be measured from the start of the outage rather than reset on every sync.
+	// Zero when the bundle isn't currently empty.
+	emptyCABundleSince time.Time
+
+	// EventDedupInterval, if positive, has emitEvent suppress a repeat of the same reason/action/message combination
+	// within this long of the last time it actually recorded that event, so a rotation storm re-triggering the same
+	// warning on every sync doesn't flood the event stream. Zero, the default, records every event as before.
+	EventDedupInterval time.Duration
+	// lastEventTimes tracks, per reason/action/message key, the last time emitEvent actually recorded that event, for
+	// EventDedupInterval to measure against.
+	lastEventTimes map[string]time.Time
+	// lastEventTimesMutex guards lastEventTimes, since emitEvent can be called from a sync running concurrently with
+	// itself only in tests, but is cheap enough to always take.
+	lastEventTimesMutex sync.Mutex
+
+	// readyProviders tracks, by provider Name(), every CA/OCSP provider that has contributed valid, non-empty
+	// content at least once, for HasBeenReady. A provider recorded here stays ready even if its content later goes
+	// empty (e.g. within EmptyCABundleGracePeriod), since HasBeenReady asks "has this ever produced something
+	// usable," not "is it non-empty right now."
+	readyProviders map[string]bool
+	// readyProvidersMutex guards readyProviders.
+	readyProvidersMutex sync.Mutex
+
+	// InitialBundle, if set, seeds the served client CA content once, on first use, with this PEM bundle, so
+	// GetConfigForClient can serve requests immediately in an air-gapped environment where clientCA's backing
+	// informer/file isn't reachable yet. The first successful syncCerts run naturally supersedes it with clientCA's
+	// real content, the same way any other content change would. Nil, the default, leaves the controller unable to
+	// serve until the first sync succeeds, as before.
+	InitialBundle []byte
+	// initialBundleSeedOnce guards seedInitialBundle, so a burst of concurrent GetConfigForClient calls before the
+	// first sync only seeds once.
+	initialBundleSeedOnce sync.Once
+
+	// NotReadyCert, if set, is served by GetConfigForClient/GetConfigForClientNoClone in place of the
+	// "configuration not ready" error they otherwise return before the first sync has completed, so a client
+	// connecting during startup can still complete a TLS handshake and receive a real HTTP response (e.g. a 503)
+	// instead of a generic, undiagnosable handshake failure. Nil, the default, preserves the existing error.
+	NotReadyCert []tls.Certificate
+
+	// CertPolicy, if set, is consulted by syncCerts for every loaded client CA certificate and every configured
+	// serving certificate, so a deployment can enforce constraints (minimum key size, allowed signature algorithms,
+	// ...) beyond what crypto/tls itself checks. Nil, the default, accepts every certificate.
+	CertPolicy CertPolicy
+	// RequireCertPolicy, when true (and CertPolicy is set), makes syncCerts fail (and therefore keep serving the
+	// last good config) if any loaded certificate violates CertPolicy. When false, the default, a violation only
+	// logs a warning and emits an event, and the certificate is still loaded.
+	RequireCertPolicy bool
 }
 
 // NewDynamicServingCertificateController returns a controller that can be used to keep a TLSConfig up to date.
@@ -75,76 +301,867 @@ func NewDynamicServingCertificateController(
 
 // GetConfigForClient is an implementation of tls.Config.GetConfigForClient
 func (c *DynamicServingCertificateController) GetConfigForClient(clientHello *tls.ClientHelloInfo) (*tls.Config, error) {
+	c.seedInitialBundle()
+
 	uncastObj := c.currentServingTLSConfig.Load()
 	if uncastObj == nil {
-		return nil, errors.New("dynamiccertificates: configuration not ready")
+		return c.notReadyTLSConfig()
+	}
+	tlsConfig, ok := uncastObj.(*tls.Config)
+	if !ok {
+		return nil, errors.New("dynamiccertificates: unexpected config type")
+	}
+	served := tlsConfig.Clone()
+	served.Certificates = selectServingCertificate(clientHello, served.Certificates)
+
+	if provider := c.listenerClientCAFor(clientHello); provider != nil {
+		pool, err := c.clientCAPoolFor(provider)
+		if err != nil {
+			return nil, fmt.Errorf("unable to build client CA pool for listener CA %q: %v", provider.Name(), err)
+		}
+		served.ClientCAs = pool
+	}
+
+	return served, nil
+}
+
+// GetConfigForClientNoClone is like GetConfigForClient but returns the live *tls.Config stored by syncCerts directly,
+// without cloning it first. Every caller is handed the same pointer, so it's only safe to use where the caller
+// guarantees it won't mutate the returned config; a mutation would be visible to every other connection served
+// concurrently and could itself race with syncCerts's next update. It exists for latency-sensitive callers (e.g. a
+// benchmark exercising ClientHello handling under high throughput) for whom tlsConfig.Clone() is measurable
+// overhead. Because it can't safely rewrite ClientCAs on the shared config, it doesn't consult per-listener client CA
+// registrations at all, nor does it narrow Certificates per ClientHello; GetConfigForClient remains the default,
+// cloning, listener-aware path.
+func (c *DynamicServingCertificateController) GetConfigForClientNoClone(clientHello *tls.ClientHelloInfo) (*tls.Config, error) {
+	c.seedInitialBundle()
+
+	uncastObj := c.currentServingTLSConfig.Load()
+	if uncastObj == nil {
+		return c.notReadyTLSConfig()
 	}
 	tlsConfig, ok := uncastObj.(*tls.Config)
 	if !ok {
 		return nil, errors.New("dynamiccertificates: unexpected config type")
 	}
+	return tlsConfig, nil
+}
 
-	return tlsConfig.Clone(), nil
+// notReadyTLSConfig returns the fallback tls.Config GetConfigForClient/GetConfigForClientNoClone serve before the
+// first sync has completed: a minimal config presenting NotReadyCert if it's set, or the "configuration not ready"
+// error otherwise.
+func (c *DynamicServingCertificateController) notReadyTLSConfig() (*tls.Config, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.NotReadyCert) == 0 {
+		return nil, errors.New("dynamiccertificates: configuration not ready")
+	}
+	notReady := c.baseTLSConfig.Clone()
+	notReady.Certificates = c.NotReadyCert
+	return notReady, nil
+}
+
+// RegisterListenerClientCA associates listenerAddr, the local address of connections accepted on some listener (as
+// reported by net.Conn.LocalAddr().String()), with a CAContentProvider whose bundle GetConfigForClient trusts
+// instead of the controller's default client CA for connections on that listener. For a server binding multiple
+// listeners (e.g. an internal and an external one) that should trust different client CAs. Safe to call
+// concurrently with GetConfigForClient.
+func (c *DynamicServingCertificateController) RegisterListenerClientCA(listenerAddr string, provider CAContentProvider) {
+	c.listenerClientCAsMutex.Lock()
+	defer c.listenerClientCAsMutex.Unlock()
+	if c.listenerClientCAs == nil {
+		c.listenerClientCAs = map[string]CAContentProvider{}
+	}
+	c.listenerClientCAs[listenerAddr] = provider
+}
+
+// listenerClientCAFor returns the CAContentProvider registered for the listener clientHello's connection was
+// accepted on, or nil if none is registered, no listener-specific CAs have been registered at all, or the
+// connection (and therefore its local address) can't be determined.
+func (c *DynamicServingCertificateController) listenerClientCAFor(clientHello *tls.ClientHelloInfo) CAContentProvider {
+	if clientHello == nil || clientHello.Conn == nil {
+		return nil
+	}
+	c.listenerClientCAsMutex.RLock()
+	defer c.listenerClientCAsMutex.RUnlock()
+	if len(c.listenerClientCAs) == 0 {
+		return nil
+	}
+	return c.listenerClientCAs[clientHello.Conn.LocalAddr().String()]
+}
+
+// clientCAPoolFor decodes provider's current CA bundle into a CertPool, honoring the same ClientCABundleDecoder and
+// FilterNonCACerts settings syncCerts uses for the default client CA.
+func (c *DynamicServingCertificateController) clientCAPoolFor(provider CAContentProvider) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	bundle := provider.CurrentCABundleContent()
+	if len(bundle) == 0 {
+		return pool, nil
+	}
+	decoder := c.ClientCABundleDecoder
+	if decoder == nil {
+		decoder = PEMBundleDecoder
+	}
+	certs, err := decoder(bundle)
+	if err != nil {
+		return nil, err
+	}
+	for _, cert := range certs {
+		if c.FilterNonCACerts && !cert.IsCA {
+			continue
+		}
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
+// effectiveClientCA returns clientCA unioned with any providers registered via AddCAProvider, or just clientCA if
+// none have been registered.
+func (c *DynamicServingCertificateController) effectiveClientCA() CAContentProvider {
+	if len(c.additionalCAProviders) == 0 {
+		return c.clientCA
+	}
+	providers := []CAContentProvider{c.clientCA}
+	for _, p := range c.additionalCAProviders {
+		providers = append(providers, p)
+	}
+	return NewUnionCAContentProvider(providers...)
 }
 
 // newTLSContent determines the next set of content for overriding the baseTLSConfig.
 func (c *DynamicServingCertificateController) newTLSContent() (*dynamicCertificateContent, error) {
 	newContent := &dynamicCertificateContent{}
 
-	currClientCABundle := c.clientCA.CurrentCABundleContent()
+	effectiveClientCA := c.effectiveClientCA()
+	currClientCABundle := effectiveClientCA.CurrentCABundleContent()
+	if c.MaxCABundleSize > 0 && len(currClientCABundle) > c.MaxCABundleSize {
+		return nil, fmt.Errorf("client ca bundle from %q is %d bytes, which exceeds the %d byte MaxCABundleSize", effectiveClientCA.Name(), len(currClientCABundle), c.MaxCABundleSize)
+	}
 	// don't remove all content.  The value was configured at one time, so continue using that.
 	// Errors reading content can be reported by lower level controllers.
 	if len(currClientCABundle) == 0 {
-		return nil, fmt.Errorf("not loading an empty client ca bundle from %q", c.clientCA.Name())
+		withinGracePeriod := false
+		if c.EmptyCABundleGracePeriod > 0 && c.currentlyServedContent != nil && len(c.currentlyServedContent.clientCA.caBundle) > 0 {
+			if c.emptyCABundleSince.IsZero() {
+				c.emptyCABundleSince = c.now()
+			}
+			withinGracePeriod = c.now().Sub(c.emptyCABundleSince) < c.EmptyCABundleGracePeriod
+		}
+		if !withinGracePeriod {
+			return nil, fmt.Errorf("not loading an empty client ca bundle from %q: %w", effectiveClientCA.Name(), ErrEmptyCABundle)
+		}
+		klog.Warningf("client ca bundle from %q is currently empty, tolerating within the %s grace period and retaining the last-good content", effectiveClientCA.Name(), c.EmptyCABundleGracePeriod)
+		newContent.clientCA = c.currentlyServedContent.clientCA
+	} else {
+		c.emptyCABundleSince = time.Time{}
+		newContent.clientCA = caBundleContent{caBundle: currClientCABundle}
+	}
+
+	if c.OCSPResponseProvider != nil {
+		newContent.ocspStaple = ocspStapleContent{staple: c.OCSPResponseProvider.CurrentOCSPResponse()}
+	}
+
+	if c.SessionTicketKeyProvider != nil {
+		newContent.sessionTicketKeys = sessionTicketKeysContent{keys: c.SessionTicketKeyProvider.CurrentSessionTicketKeys()}
+	}
+
+	if c.ServingCertProvider != nil {
+		newContent.servingCert = servingCertContent{certs: c.ServingCertProvider.CurrentServingCertificate()}
+	} else {
+		newContent.servingCert = servingCertContent{certs: c.baseTLSConfig.Certificates}
+	}
+
+	if c.RenegotiationProvider != nil {
+		newContent.renegotiation = renegotiationContent{support: c.RenegotiationProvider.CurrentRenegotiationSupport()}
 	}
-	newContent.clientCA = caBundleContent{caBundle: currClientCABundle}
 
 	return newContent, nil
 }
 
+// seedInitialBundle installs InitialBundle as the served client CA content, if set, the first time it's called and
+// only if nothing has been served yet -- a sync that's already run, whether via syncCerts or a prior call here,
+// always wins. Safe to call from multiple goroutines; only the first call after construction does any work.
+func (c *DynamicServingCertificateController) seedInitialBundle() {
+	if len(c.InitialBundle) == 0 {
+		return
+	}
+	c.initialBundleSeedOnce.Do(func() {
+		if c.currentServingTLSConfig.Load() != nil {
+			return
+		}
+
+		decoder := c.ClientCABundleDecoder
+		if decoder == nil {
+			decoder = PEMBundleDecoder
+		}
+		certs, err := decoder(c.InitialBundle)
+		if err != nil {
+			klog.Warningf("dynamiccertificates: failed to load InitialBundle, not seeding: %v", err)
+			return
+		}
+
+		pool := x509.NewCertPool()
+		parsedCerts := make([]*x509.Certificate, 0, len(certs))
+		for _, cert := range certs {
+			pool.AddCert(cert)
+			parsedCerts = append(parsedCerts, cert)
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.currentServingTLSConfig.Load() != nil {
+			return
+		}
+
+		newTLSConfigCopy := c.baseTLSConfig.Clone()
+		newTLSConfigCopy.ClientCAs = pool
+
+		c.currentServingTLSConfig.Store(newTLSConfigCopy)
+		c.currentlyServedContent = &dynamicCertificateContent{clientCA: caBundleContent{caBundle: c.InitialBundle}}
+		c.currentClientCACerts = parsedCerts
+		c.lastContentChangeTime = c.now()
+	})
+}
+
+// AddCAProvider registers an additional CA provider, unioned with clientCA when building the served client CA pool,
+// keyed by provider.Name() so it can later be removed via RemoveCAProvider. It forces a resync so the addition takes
+// effect immediately; mu makes this safe to call concurrently with the controller's own worker goroutine or with
+// another reconfiguration call.
+func (c *DynamicServingCertificateController) AddCAProvider(provider CAContentProvider) error {
+	c.mu.Lock()
+	if c.additionalCAProviders == nil {
+		c.additionalCAProviders = map[string]CAContentProvider{}
+	}
+	c.additionalCAProviders[provider.Name()] = provider
+	c.mu.Unlock()
+
+	return c.syncCerts()
+}
+
+// RemoveCAProvider removes a CA provider previously registered via AddCAProvider by name and forces a resync so the
+// served client CA pool no longer trusts its content. It reports whether a provider with that name was found;
+// removing the clientCA configured via NewDynamicServingCertificateController or ReconfigureAll isn't supported
+// here, since callers already own replacing that one directly.
+func (c *DynamicServingCertificateController) RemoveCAProvider(name string) (bool, error) {
+	c.mu.Lock()
+	_, ok := c.additionalCAProviders[name]
+	if ok {
+		delete(c.additionalCAProviders, name)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, c.syncCerts()
+}
+
+// RemoveSNICert removes the serving certificate configured for the given SNI hostname.
+//
+// NOTE: this controller doesn't yet track a set of named, per-SNI serving certificates -- baseTLSConfig and
+// ServingCertProvider each carry a single, unnamed certificate list served for every SNI. There's nothing
+// hostname-addressable to remove yet, so this always errors until that support is added.
+func (c *DynamicServingCertificateController) RemoveSNICert(hostname string) error {
+	return fmt.Errorf("dynamiccertificates: no SNI certificate registered for %q: this controller does not yet support per-SNI serving certificates", hostname)
+}
+
 // syncCerts gets newTLSContent, if it has changed from the existing, the content is parsed and stored for usage in
 // GetConfigForClient.
 func (c *DynamicServingCertificateController) syncCerts() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	syncsTotal.Inc()
+
 	newContent, err := c.newTLSContent()
 	if err != nil {
 		return err
 	}
-	// if the content is the same as what we currently have, we can simply skip it.  This works because we are single
-	// threaded.  If you ever make this multi-threaded, add a lock.
+	// if the content is the same as what we currently have, we can simply skip it. mu's write lock, held for this
+	// whole call, is what makes that safe against a concurrent sync or reconfiguration.
 	if newContent.Equal(c.currentlyServedContent) {
 		return nil
 	}
+	syncsContentChangedTotal.Inc()
+
+	// If the client CA bundle bytes are identical to what we last served, skip decoding, CertPolicy-validating, and
+	// re-eventing every client CA cert again -- something else in newContent changed (e.g. the serving cert rotated),
+	// but the already-validated pool from the last sync is still exactly right, so just reuse it.
+	if oldContent := c.currentlyServedContent; oldContent != nil && newContent.clientCA.Equal(&oldContent.clientCA) {
+		if uncastObj := c.currentServingTLSConfig.Load(); uncastObj != nil {
+			if oldTLSConfig, ok := uncastObj.(*tls.Config); ok && oldTLSConfig.ClientCAs != nil {
+				return c.finishSyncCerts(newContent, oldTLSConfig.ClientCAs, c.currentClientCACerts)
+			}
+		}
+	}
 
 	// parse new content to add to TLSConfig
 	newClientCAPool := x509.NewCertPool()
+	var newClientCACerts []*x509.Certificate
 	if len(newContent.clientCA.caBundle) > 0 {
-		newClientCAs, err := cert.ParseCertsPEM(newContent.clientCA.caBundle)
+		decoder := c.ClientCABundleDecoder
+		if decoder == nil {
+			decoder = PEMBundleDecoder
+		}
+		newClientCAs, err := decoder(newContent.clientCA.caBundle)
 		if err != nil {
 			return fmt.Errorf("unable to load client CA file: %v", err)
 		}
 		for i, cert := range newClientCAs {
+			if c.FilterNonCACerts && !cert.IsCA {
+				klog.V(2).Infof("skipping non-CA client CA [%d/%q]: %s", i, c.clientCA.Name(), GetHumanCertDetail(cert))
+				continue
+			}
 			klog.V(2).Infof("loaded client CA [%d/%q]: %s", i, c.clientCA.Name(), GetHumanCertDetail(cert))
-			if c.eventRecorder != nil {
-				c.eventRecorder.Eventf(nil, nil, v1.EventTypeWarning, "TLSConfigChanged", "CACertificateReload", "loaded client CA [%d/%q]: %s", i, c.clientCA.Name(), GetHumanCertDetail(cert))
+			c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "CACertificateReload", "loaded client CA [%d/%q]: %s", i, c.clientCA.Name(), GetHumanCertDetail(cert))
+
+			if c.CertPolicy != nil {
+				if err := c.CertPolicy.Validate(cert); err != nil {
+					msg := fmt.Sprintf("client CA [%d/%q] failed certificate policy: %v", i, c.clientCA.Name(), err)
+					if c.RequireCertPolicy {
+						return errors.New(msg)
+					}
+					klog.Warning(msg)
+					c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "CertPolicyViolation", msg)
+				}
 			}
 
 			newClientCAPool.AddCert(cert)
+			newClientCACerts = append(newClientCACerts, cert)
 		}
+		trustedClientCAsGauge.WithLabelValues(c.clientCA.Name()).Set(float64(len(newClientCACerts)))
 	}
 
+	return c.finishSyncCerts(newContent, newClientCAPool, newClientCACerts)
+}
+
+// finishSyncCerts builds the served tls.Config out of newContent and the already-resolved client CA pool/certs
+// (freshly parsed, or reused unchanged from the last sync), applies every remaining dynamic setting, validates the
+// result, and commits it as the currently served content. Split out of syncCerts so the client CA parsing block,
+// the expensive part on the hot path this exists to let callers skip, can short-circuit straight here.
+func (c *DynamicServingCertificateController) finishSyncCerts(newContent *dynamicCertificateContent, newClientCAPool *x509.CertPool, newClientCACerts []*x509.Certificate) error {
 	// make a copy and override the dynamic pieces which have changed.
 	newTLSConfigCopy := c.baseTLSConfig.Clone()
 	newTLSConfigCopy.ClientCAs = newClientCAPool
 
+	if len(newTLSConfigCopy.Certificates) == 0 && c.ServingCertProvider != nil {
+		newTLSConfigCopy.Certificates = c.ServingCertProvider.CurrentServingCertificate()
+		if len(newTLSConfigCopy.Certificates) == 0 {
+			if len(c.MaintenanceCert) > 0 {
+				newTLSConfigCopy.Certificates = c.MaintenanceCert
+				msg := fmt.Sprintf("no serving certificate available from baseTLSConfig or ServingCertProvider %q; serving MaintenanceCert instead", c.ServingCertProvider.Name())
+				klog.Warning(msg)
+				c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "ServingMaintenanceCert", msg)
+			} else if c.currentServingTLSConfig.Load() == nil {
+				return fmt.Errorf("no serving certificate available from baseTLSConfig or ServingCertProvider %q", c.ServingCertProvider.Name())
+			}
+		}
+	}
+
+	if c.IntermediateProvider != nil {
+		newTLSConfigCopy.Certificates = appendIntermediateCertificates(newTLSConfigCopy.Certificates, c.IntermediateProvider)
+	}
+
+	if len(newContent.ocspStaple.staple) > 0 && len(newTLSConfigCopy.Certificates) > 0 {
+		// Clone shares the Certificates slice's backing array with baseTLSConfig, so copy before mutating in place.
+		stapledCerts := make([]tls.Certificate, len(newTLSConfigCopy.Certificates))
+		copy(stapledCerts, newTLSConfigCopy.Certificates)
+		for i := range stapledCerts {
+			stapledCerts[i].OCSPStaple = newContent.ocspStaple.staple
+		}
+		newTLSConfigCopy.Certificates = stapledCerts
+	}
+
+	if len(newContent.sessionTicketKeys.keys) > 0 {
+		newTLSConfigCopy.SetSessionTicketKeys(newContent.sessionTicketKeys.keys)
+	}
+
+	if c.RenegotiationProvider != nil {
+		newTLSConfigCopy.Renegotiation = newContent.renegotiation.support
+	}
+
+	if c.MaxClientChainDepth > 0 {
+		newTLSConfigCopy.VerifyPeerCertificate = verifyPeerCertificateChainDepth(newTLSConfigCopy.VerifyPeerCertificate, c.MaxClientChainDepth)
+	}
+
+	if len(c.RequiredIssuerSubjects) > 0 {
+		newTLSConfigCopy.VerifyPeerCertificate = verifyPeerCertificateRequiredIssuer(newTLSConfigCopy.VerifyPeerCertificate, c.RequiredIssuerSubjects)
+	}
+
+	if missing := missingServerNames(c.ExpectedServerNames, newTLSConfigCopy.Certificates); len(missing) > 0 {
+		msg := fmt.Sprintf("serving certificate does not cover expected server name(s) %v", missing)
+		if c.RequireServerNamesMatch {
+			return errors.New(msg)
+		}
+		klog.Warning(msg)
+		c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "ServerNameMismatch", msg)
+	}
+
+	if c.CertPolicy != nil {
+		if violations := servingCertPolicyViolations(c.CertPolicy, newTLSConfigCopy.Certificates); len(violations) > 0 {
+			msg := fmt.Sprintf("serving certificate(s) failed certificate policy: %v", violations)
+			if c.RequireCertPolicy {
+				return errors.New(msg)
+			}
+			klog.Warning(msg)
+			c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "CertPolicyViolation", msg)
+		}
+	}
+
+	if c.CheckSCTPresence {
+		if missing := certsWithoutSCT(newTLSConfigCopy.Certificates); len(missing) > 0 {
+			msg := fmt.Sprintf("serving certificate(s) missing embedded SCTs: %v", missing)
+			if c.RequireSCTPresence {
+				return errors.New(msg)
+			}
+			klog.Warning(msg)
+			c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "MissingSCT", msg)
+		}
+	}
+
+	if c.CheckServerAuthEKU {
+		if missing := certsWithoutServerAuthEKU(newTLSConfigCopy.Certificates); len(missing) > 0 {
+			msg := fmt.Sprintf("serving certificate(s) missing serverAuth extended key usage: %v", missing)
+			if c.RequireServerAuthEKU {
+				return errors.New(msg)
+			}
+			klog.Warning(msg)
+			c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "MissingServerAuthEKU", msg)
+		}
+	}
+
+	if err := c.validateTLSConfig(newTLSConfigCopy); err != nil {
+		msg := fmt.Sprintf("new TLS configuration failed validation, retaining previous configuration: %v", err)
+		klog.Warning(msg)
+		c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "ValidationFailed", msg)
+		return errors.New(msg)
+	}
+
+	if added, removed := diffClientCACerts(c.currentClientCACerts, newClientCACerts); len(added) > 0 || len(removed) > 0 {
+		c.emitEvent(v1.EventTypeNormal, "TLSConfigChanged", "ClientCABundleChanged", describeClientCACertDiff(added, removed))
+		if c.ClientCABundleChangeHandler != nil {
+			c.ClientCABundleChangeHandler(added, removed)
+		}
+	}
+
 	// store new values of content for serving.
 	c.currentServingTLSConfig.Store(newTLSConfigCopy)
-	c.currentlyServedContent = newContent // this is single threaded, so we have no locking issue
+	c.currentlyServedContent = newContent // safe: finishSyncCerts only ever runs under syncCerts's write lock
+	c.currentClientCACerts = newClientCACerts
+	c.lastContentChangeTime = c.now()
+	c.recordContentChange(newClientCACerts, newTLSConfigCopy.Certificates)
+
+	if c.PublishTarget != nil {
+		if err := c.publishCABundle(newContent.clientCA.caBundle); err != nil {
+			msg := publishTargetFailureMessage(c.PublishTarget, err)
+			klog.Warning(msg)
+			c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "CABundlePublishFailed", msg)
+		}
+	}
+
+	return nil
+}
+
+// validateTLSConfig proves tlsConfig actually works by running a real TLS handshake against it over an in-memory
+// pipe, so a config that's structurally fine but unusable (e.g. a certificate/key mismatch) is caught here rather
+// than at the first real client connection. Client certificate enforcement isn't part of what's being proven here,
+// so the validation handshake always runs with client auth disabled regardless of tlsConfig.ClientAuth. Skipped
+// entirely when tlsConfig has no serving certificate, since there's nothing yet to hand a client.
+func (c *DynamicServingCertificateController) validateTLSConfig(tlsConfig *tls.Config) error {
+	if len(tlsConfig.Certificates) == 0 {
+		return nil
+	}
+
+	serverTLSConfig := tlsConfig.Clone()
+	serverTLSConfig.ClientAuth = tls.NoClientCert
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- tls.Server(serverConn, serverTLSConfig).Handshake()
+	}()
+
+	clientErr := tls.Client(clientConn, &tls.Config{InsecureSkipVerify: true}).Handshake()
+	if err := <-serverErr; err != nil {
+		return fmt.Errorf("self-handshake failed on the server side: %v", err)
+	}
+	if clientErr != nil {
+		return fmt.Errorf("self-handshake failed on the client side: %v", clientErr)
+	}
+	return nil
+}
+
+// now returns the current time, using nowFn if a test has overridden it.
+func (c *DynamicServingCertificateController) now() time.Time {
+	if c.nowFn != nil {
+		return c.nowFn()
+	}
+	return time.Now()
+}
+
+// emitEvent records an event through eventRecorder, formatting note/args exactly once so the dedup key and the
+// recorded message agree. If EventDedupInterval is positive and a prior emitEvent call recorded the same
+// reason/action/message combination more recently than that, this call is dropped instead of recorded, so a chatty
+// rotation storm doesn't produce one event per sync. A nil eventRecorder makes this a no-op, as at every existing
+// call site before this method was introduced.
+func (c *DynamicServingCertificateController) emitEvent(eventtype, reason, action, note string, args ...interface{}) {
+	c.eventRecorderMutex.RLock()
+	eventRecorder := c.eventRecorder
+	c.eventRecorderMutex.RUnlock()
+	if eventRecorder == nil {
+		return
+	}
+	message := fmt.Sprintf(note, args...)
+
+	if c.EventDedupInterval > 0 {
+		key := reason + "/" + action + "/" + message
+		c.lastEventTimesMutex.Lock()
+		last, seen := c.lastEventTimes[key]
+		now := c.now()
+		if seen && now.Sub(last) < c.EventDedupInterval {
+			c.lastEventTimesMutex.Unlock()
+			return
+		}
+		if c.lastEventTimes == nil {
+			c.lastEventTimes = map[string]time.Time{}
+		}
+		c.lastEventTimes[key] = now
+		c.lastEventTimesMutex.Unlock()
+	}
+
+	eventRecorder.Eventf(nil, nil, eventtype, reason, action, "%s", message)
+}
+
+// SetEventRecorder replaces the event recorder used by emitEvent, so events recorded after a handoff (e.g. a leader
+// election taking over the controller's reference object) attribute to the new recorder's involved object instead
+// of the old one. Safe to call concurrently with a running sync.
+func (c *DynamicServingCertificateController) SetEventRecorder(eventRecorder events.EventRecorder) {
+	c.eventRecorderMutex.Lock()
+	defer c.eventRecorderMutex.Unlock()
+	c.eventRecorder = eventRecorder
+}
+
+// CurrentContentAge returns the time elapsed since currentlyServedContent last actually changed, as opposed to the
+// last time syncCerts ran. Combined with a certificate expiry gauge, a growing age here indicates rotation is stuck
+// rather than simply not yet due. Returns zero before the first successful sync.
+func (c *DynamicServingCertificateController) CurrentContentAge() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.lastContentChangeTime.IsZero() {
+		return 0
+	}
+	return c.now().Sub(c.lastContentChangeTime)
+}
+
+// QueueLen returns the number of pending work items in the sync queue. The controller uses a single well-known key,
+// so this is normally 0 (idle) or 1 (a sync is pending or in flight); a value stuck above 0 combined with the retry
+// metrics can indicate syncCerts is failing repeatedly under rate-limited backoff.
+func (c *DynamicServingCertificateController) QueueLen() int {
+	return c.queue.Len()
+}
+
+// HasBeenReady reports whether every currently registered content provider -- clientCA, each provider added via
+// AddCAProvider, and OCSPResponseProvider, if set -- has contributed valid, non-empty content at least once. A
+// provider registered but never yet observed with content (e.g. its backing ConfigMap/Secret hasn't synced) keeps
+// this false; a controller with no providers registered at all is also not ready. SessionTicketKeyProvider is
+// deliberately excluded, since it documents empty as a legitimate steady state (leave crypto/tls's own default key),
+// not an unready one.
+func (c *DynamicServingCertificateController) HasBeenReady() bool {
+	providers := c.readinessProviders()
+	if len(providers) == 0 {
+		return false
+	}
+	for name, hasContent := range providers {
+		if hasContent {
+			c.markProviderReady(name)
+			continue
+		}
+		if !c.isProviderReady(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// readinessProviders returns, by Name(), every provider HasBeenReady gates on, along with whether it currently has
+// non-empty content.
+func (c *DynamicServingCertificateController) readinessProviders() map[string]bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	providers := map[string]bool{}
+	if c.clientCA != nil {
+		providers[c.clientCA.Name()] = len(c.clientCA.CurrentCABundleContent()) > 0
+	}
+	for name, provider := range c.additionalCAProviders {
+		providers[name] = len(provider.CurrentCABundleContent()) > 0
+	}
+	if c.OCSPResponseProvider != nil {
+		providers[c.OCSPResponseProvider.Name()] = len(c.OCSPResponseProvider.CurrentOCSPResponse()) > 0
+	}
+	return providers
+}
+
+func (c *DynamicServingCertificateController) markProviderReady(name string) {
+	c.readyProvidersMutex.Lock()
+	defer c.readyProvidersMutex.Unlock()
+	if c.readyProviders == nil {
+		c.readyProviders = map[string]bool{}
+	}
+	c.readyProviders[name] = true
+}
+
+func (c *DynamicServingCertificateController) isProviderReady(name string) bool {
+	c.readyProvidersMutex.Lock()
+	defer c.readyProvidersMutex.Unlock()
+	return c.readyProviders[name]
+}
+
+// ReconfigureAll atomically replaces the base TLS config, the client CA provider, and the serving cert provider in a
+// single step, then forces one sync so the served config reflects the new set before returning. This avoids a
+// transient window where syncCerts could run with only some of the three swapped in. If the forced sync fails, the
+// previous baseTLSConfig, clientCA, and servingCertProvider are all restored so a caller never leaves the controller
+// half-migrated. servingCertProvider may be nil, matching the field it replaces.
+func (c *DynamicServingCertificateController) ReconfigureAll(baseTLSConfig *tls.Config, clientCA CAContentProvider, servingCertProvider ServingCertProvider) error {
+	c.mu.Lock()
+	oldBaseTLSConfig := c.baseTLSConfig.Clone()
+	oldClientCA := c.clientCA
+	oldServingCertProvider := c.ServingCertProvider
+	c.baseTLSConfig = *baseTLSConfig.Clone()
+	c.clientCA = clientCA
+	c.ServingCertProvider = servingCertProvider
+	c.mu.Unlock()
+
+	if err := c.syncCerts(); err != nil {
+		// roll back to the prior configuration so we never serve with a mismatched or half-applied set.
+		c.mu.Lock()
+		c.baseTLSConfig = *oldBaseTLSConfig.Clone()
+		c.clientCA = oldClientCA
+		c.ServingCertProvider = oldServingCertProvider
+		c.mu.Unlock()
+		return fmt.Errorf("failed to reconfigure, rolled back: %v", err)
+	}
 
 	return nil
 }
 
+// verifyPeerCertificateChainDepth returns a tls.Config.VerifyPeerCertificate func that first runs previous, if any,
+// then rejects any verified chain longer than maxDepth certificates. crypto/tls calls this after building and
+// trusting chains against ClientCAs, so it only bounds depth among chains already considered valid.
+func verifyPeerCertificateChainDepth(previous func([][]byte, [][]*x509.Certificate) error, maxDepth int) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if previous != nil {
+			if err := previous(rawCerts, verifiedChains); err != nil {
+				return err
+			}
+		}
+		for _, chain := range verifiedChains {
+			if len(chain) > maxDepth {
+				return fmt.Errorf("client certificate chain length %d exceeds maximum allowed depth %d", len(chain), maxDepth)
+			}
+		}
+		return nil
+	}
+}
+
+// verifyPeerCertificateRequiredIssuer returns a tls.Config.VerifyPeerCertificate func that first runs previous, if
+// any, then rejects a client certificate unless one of its verified chains contains a certificate whose Subject
+// matches a name in requiredSubjects. This lets a deployment require a specific intermediate issuer even though
+// ClientCAs alone can only express trust down to a root CA.
+func verifyPeerCertificateRequiredIssuer(previous func([][]byte, [][]*x509.Certificate) error, requiredSubjects []string) func([][]byte, [][]*x509.Certificate) error {
+	required := make(map[string]bool, len(requiredSubjects))
+	for _, subject := range requiredSubjects {
+		required[subject] = true
+	}
+
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if previous != nil {
+			if err := previous(rawCerts, verifiedChains); err != nil {
+				return err
+			}
+		}
+		for _, chain := range verifiedChains {
+			for _, cert := range chain {
+				if required[cert.Subject.String()] {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("client certificate chain does not include a required issuer subject: %v", requiredSubjects)
+	}
+}
+
+// leafCertificate returns tlsCert's leaf, parsing it from raw bytes if it hasn't already been populated (e.g. the
+// certificate was loaded from disk rather than obtained by handshaking). Returns nil if there's nothing to parse.
+func leafCertificate(tlsCert tls.Certificate) *x509.Certificate {
+	if tlsCert.Leaf != nil {
+		return tlsCert.Leaf
+	}
+	if len(tlsCert.Certificate) == 0 {
+		return nil
+	}
+	parsed, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		return nil
+	}
+	return parsed
+}
+
+// sctListExtensionOID identifies the X.509v3 extension RFC 6962 uses to embed a SignedCertificateTimestampList in a
+// certificate.
+var sctListExtensionOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// certsWithoutSCT returns the subject common names of every leaf certificate in certs that has no embedded
+// SignedCertificateTimestampList extension. A tls.Certificate whose Leaf hasn't been populated is parsed from its
+// raw bytes to obtain the leaf.
+func certsWithoutSCT(certs []tls.Certificate) []string {
+	var missing []string
+	for _, tlsCert := range certs {
+		leaf := leafCertificate(tlsCert)
+		if leaf == nil {
+			continue
+		}
+		hasSCT := false
+		for _, ext := range leaf.Extensions {
+			if ext.Id.Equal(sctListExtensionOID) {
+				hasSCT = true
+				break
+			}
+		}
+		if !hasSCT {
+			missing = append(missing, leaf.Subject.CommonName)
+		}
+	}
+	return missing
+}
+
+// certsWithoutServerAuthEKU returns the subject common names of every leaf certificate in certs whose ExtKeyUsage
+// does not include x509.ExtKeyUsageServerAuth. A tls.Certificate whose Leaf hasn't been populated is parsed from
+// its raw bytes to obtain the leaf.
+func certsWithoutServerAuthEKU(certs []tls.Certificate) []string {
+	var missing []string
+	for _, tlsCert := range certs {
+		leaf := leafCertificate(tlsCert)
+		if leaf == nil {
+			continue
+		}
+		hasServerAuth := false
+		for _, eku := range leaf.ExtKeyUsage {
+			if eku == x509.ExtKeyUsageServerAuth {
+				hasServerAuth = true
+				break
+			}
+		}
+		if !hasServerAuth {
+			missing = append(missing, leaf.Subject.CommonName)
+		}
+	}
+	return missing
+}
+
+// servingCertPolicyViolations returns policy.Validate's error string for each certificate in certs that fails it. A
+// tls.Certificate whose Leaf hasn't been populated is parsed from its raw bytes to obtain the leaf.
+func servingCertPolicyViolations(policy CertPolicy, certs []tls.Certificate) []string {
+	var violations []string
+	for _, tlsCert := range certs {
+		leaf := leafCertificate(tlsCert)
+		if leaf == nil {
+			continue
+		}
+		if err := policy.Validate(leaf); err != nil {
+			violations = append(violations, err.Error())
+		}
+	}
+	return violations
+}
+
+// missingServerNames returns the subset of expected that isn't covered, per x509.Certificate.VerifyHostname, by any
+// leaf certificate in certs. A tls.Certificate whose Leaf hasn't been populated (e.g. never handshaked with) is
+// parsed from its raw bytes to obtain the leaf.
+func missingServerNames(expected []string, certs []tls.Certificate) []string {
+	var missing []string
+	for _, name := range expected {
+		covered := false
+		for _, tlsCert := range certs {
+			leaf := leafCertificate(tlsCert)
+			if leaf != nil && leaf.VerifyHostname(name) == nil {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// CurrentClientCAPool returns a defensive clone of the currently trusted client CA pool, built fresh from the same
+// certs backing the served TLS config's ClientCAs (post FilterNonCACerts filtering). This lets other components,
+// such as the request header or client-cert authenticators, share the pool the serving layer trusts and stay in
+// sync across rotation without holding a reference to (or being able to mutate) the live pool.
+func (c *DynamicServingCertificateController) CurrentClientCAPool() *x509.CertPool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pool := x509.NewCertPool()
+	for _, cert := range c.currentClientCACerts {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// IsClientCATrusted reports whether cert is currently trusted as a client CA, comparing by SHA-256 fingerprint
+// against the certs backing the served config's ClientCAs pool (post FilterNonCACerts filtering). This is more
+// precise than comparing subjects, which two certs with different keys can share.
+func (c *DynamicServingCertificateController) IsClientCATrusted(cert *x509.Certificate) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	fingerprint := sha256.Sum256(cert.Raw)
+	for _, trusted := range c.currentClientCACerts {
+		if sha256.Sum256(trusted.Raw) == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+// GetServingCertExpiry returns the NotAfter of the first configured serving certificate, and whether a serving
+// certificate is currently configured at all. It reads currentServingTLSConfig, the same atomic value
+// GetConfigForClient serves from, so it reflects the live certificate after a rotation rather than whatever was
+// passed to NewDynamicServingCertificateController or ReconfigureAll originally.
+func (c *DynamicServingCertificateController) GetServingCertExpiry() (time.Time, bool) {
+	uncastObj := c.currentServingTLSConfig.Load()
+	if uncastObj == nil {
+		return time.Time{}, false
+	}
+	tlsConfig, ok := uncastObj.(*tls.Config)
+	if !ok || len(tlsConfig.Certificates) == 0 {
+		return time.Time{}, false
+	}
+	leaf := leafCertificate(tlsConfig.Certificates[0])
+	if leaf == nil {
+		return time.Time{}, false
+	}
+	return leaf.NotAfter, true
+}
+
 // RunOnce runs a single sync step to ensure that we have a valid starting configuration.
 func (c *DynamicServingCertificateController) RunOnce() error {
+	return c.ForceSync()
+}
+
+// ForceSync synchronously runs a sync cycle right now, outside the normal queue-driven cadence syncCerts is usually
+// triggered through. It's just syncCerts under another name, kept as its own exported entry point so a caller
+// forcing an immediate sync (e.g. after some out-of-band change a running informer hasn't reported yet) can say so
+// without reaching for the queue. Safe to call concurrently with the controller's own worker or with a
+// reconfiguration call; mu's write lock, held for syncCerts's whole duration, is what makes that true.
+func (c *DynamicServingCertificateController) ForceSync() error {
 	return c.syncCerts()
 }
 
@@ -180,14 +1197,22 @@ func (c *DynamicServingCertificateController) processNextWorkItem() bool {
 	if quit {
 		return false
 	}
-	defer c.queue.Done(dsKey)
 
 	err := c.syncCerts()
 	if err == nil {
 		c.queue.Forget(dsKey)
+		c.queue.Done(dsKey)
+		c.pendingMu.Lock()
+		// Done() re-adds dsKey to the queue if Enqueue() marked it dirty again while syncCerts was running above
+		// (see client-go's workqueue), so queue.Len() > 0 here means a fresh sync is already queued and pending
+		// must stay true -- clearing it unconditionally would let that concurrent Enqueue() be silently dropped
+		// by Shutdown.
+		c.pending = c.queue.Len() > 0
+		c.pendingMu.Unlock()
 		return true
 	}
 
+	c.queue.Done(dsKey)
 	utilruntime.HandleError(fmt.Errorf("%v failed with : %v", dsKey, err))
 	c.queue.AddRateLimited(dsKey)
 
@@ -196,5 +1221,40 @@ func (c *DynamicServingCertificateController) processNextWorkItem() bool {
 
 // Enqueue a method to allow separate control loops to cause the certificate controller to trigger and read content.
 func (c *DynamicServingCertificateController) Enqueue() {
+	if atomic.LoadInt32(&c.shuttingDown) == 1 {
+		return
+	}
+	c.pendingMu.Lock()
+	c.pending = true
+	c.pendingMu.Unlock()
 	c.queue.Add(workItemKey)
 }
+
+// Shutdown stops the controller from accepting new work, waits (bounded by ctx) for the queue to drain and any
+// in-flight sync to finish, and then shuts the queue down. It returns true if everything drained cleanly before ctx
+// was done, and false if ctx expired first, so tests and operators doing a clean shutdown can tell whether a
+// pending sync was lost.
+func (c *DynamicServingCertificateController) Shutdown(ctx context.Context) bool {
+	atomic.StoreInt32(&c.shuttingDown, 1)
+
+	drained := true
+	for {
+		c.pendingMu.Lock()
+		pending := c.pending
+		c.pendingMu.Unlock()
+		if !pending {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			drained = false
+		case <-time.After(10 * time.Millisecond):
+			continue
+		}
+		break
+	}
+
+	c.queue.ShutDown()
+	return drained
+}
@@ -17,12 +17,384 @@ limitations under the License.
 package dynamiccertificates
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/events"
+	certutil "k8s.io/client-go/util/cert"
 )
 
+// newTestCACertPEM returns a freshly minted, PEM-encoded self-signed CA certificate for the given common name.
+func newTestCACertPEM(t testing.TB, commonName string) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: commonName, Organization: []string{"test"}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})
+}
+
+// newTestLeafCertPEM returns a freshly minted, PEM-encoded self-signed certificate with IsCA set to false, as if a
+// leaf server certificate had been pasted into a client CA bundle by mistake.
+func newTestLeafCertPEM(t *testing.T, commonName string) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  false,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// newTestServingCert returns a freshly minted, self-signed tls.Certificate whose SAN DNS names are dnsNames.
+func newTestServingCert(t *testing.T, dnsNames ...string) tls.Certificate {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "serving"},
+		DNSNames:              dnsNames,
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestMissingServerNames(t *testing.T) {
+	certs := []tls.Certificate{newTestServingCert(t, "api.example.com", "*.svc.cluster.local")}
+
+	tests := []struct {
+		name     string
+		expected []string
+		want     []string
+	}{
+		{name: "fully covered", expected: []string{"api.example.com"}, want: nil},
+		{name: "wildcard covered", expected: []string{"foo.svc.cluster.local"}, want: nil},
+		{name: "missing", expected: []string{"api.example.com", "other.example.com"}, want: []string{"other.example.com"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := missingServerNames(test.expected, certs)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSyncCertsRequireServerNamesMatch(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:           tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "api.example.com")}},
+		clientCA:                NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		ExpectedServerNames:     []string{"other.example.com"},
+		RequireServerNamesMatch: true,
+	}
+	if err := c.RunOnce(); err == nil {
+		t.Fatal("expected RunOnce to fail when the serving cert doesn't cover an expected server name")
+	}
+
+	c.RequireServerNamesMatch = false
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("expected a mismatch to only warn when RequireServerNamesMatch is false, got: %v", err)
+	}
+}
+
+func TestVerifyPeerCertificateChainDepth(t *testing.T) {
+	root := &x509.Certificate{SerialNumber: big.NewInt(1)}
+	intermediate := &x509.Certificate{SerialNumber: big.NewInt(2)}
+	leaf := &x509.Certificate{SerialNumber: big.NewInt(3)}
+
+	verify := verifyPeerCertificateChainDepth(nil, 2)
+
+	if err := verify(nil, [][]*x509.Certificate{{leaf, intermediate}}); err != nil {
+		t.Errorf("expected a chain within the limit to be accepted, got: %v", err)
+	}
+	if err := verify(nil, [][]*x509.Certificate{{leaf, intermediate, root}}); err == nil {
+		t.Error("expected a chain exceeding the limit to be rejected")
+	}
+}
+
+func TestVerifyPeerCertificateChainDepthComposesWithPrevious(t *testing.T) {
+	previousCalled := false
+	previous := func([][]byte, [][]*x509.Certificate) error {
+		previousCalled = true
+		return errors.New("previous check failed")
+	}
+
+	verify := verifyPeerCertificateChainDepth(previous, 5)
+	if err := verify(nil, nil); err == nil || !previousCalled {
+		t.Error("expected the previous VerifyPeerCertificate to run and its error to be surfaced")
+	}
+}
+
+func TestSyncCertsMaxClientChainDepth(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		clientCA:            NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		MaxClientChainDepth: 3,
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	served, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if served.VerifyPeerCertificate == nil {
+		t.Fatal("expected VerifyPeerCertificate to be installed when MaxClientChainDepth is set")
+	}
+	shortChain := make([]*x509.Certificate, 3)
+	if err := served.VerifyPeerCertificate(nil, [][]*x509.Certificate{shortChain}); err != nil {
+		t.Errorf("expected a chain at the limit to be accepted, got: %v", err)
+	}
+	longChain := make([]*x509.Certificate, 4)
+	if err := served.VerifyPeerCertificate(nil, [][]*x509.Certificate{longChain}); err == nil {
+		t.Error("expected a chain exceeding the limit to be rejected")
+	}
+}
+
+func TestVerifyPeerCertificateRequiredIssuer(t *testing.T) {
+	requiredIntermediate := &x509.Certificate{Subject: pkix.Name{CommonName: "required-intermediate"}}
+	siblingIntermediate := &x509.Certificate{Subject: pkix.Name{CommonName: "sibling-intermediate"}}
+	root := &x509.Certificate{Subject: pkix.Name{CommonName: "root"}}
+	leaf := &x509.Certificate{Subject: pkix.Name{CommonName: "leaf"}}
+
+	verify := verifyPeerCertificateRequiredIssuer(nil, []string{requiredIntermediate.Subject.String()})
+
+	if err := verify(nil, [][]*x509.Certificate{{leaf, requiredIntermediate, root}}); err != nil {
+		t.Errorf("expected a chain through the required intermediate to be accepted, got: %v", err)
+	}
+	if err := verify(nil, [][]*x509.Certificate{{leaf, siblingIntermediate, root}}); err == nil {
+		t.Error("expected a chain through a sibling intermediate under the same root to be rejected")
+	}
+}
+
+func TestVerifyPeerCertificateRequiredIssuerComposesWithPrevious(t *testing.T) {
+	previousCalled := false
+	previous := func([][]byte, [][]*x509.Certificate) error {
+		previousCalled = true
+		return errors.New("previous check failed")
+	}
+
+	verify := verifyPeerCertificateRequiredIssuer(previous, []string{"required"})
+	if err := verify(nil, nil); err == nil || !previousCalled {
+		t.Error("expected the previous VerifyPeerCertificate to run and its error to be surfaced")
+	}
+}
+
+func TestSyncCertsRequiredIssuerSubjects(t *testing.T) {
+	requiredIntermediate := &x509.Certificate{Subject: pkix.Name{CommonName: "required-intermediate"}}
+	siblingIntermediate := &x509.Certificate{Subject: pkix.Name{CommonName: "sibling-intermediate"}}
+	leaf := &x509.Certificate{Subject: pkix.Name{CommonName: "leaf"}}
+
+	c := &DynamicServingCertificateController{
+		clientCA:               NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		RequiredIssuerSubjects: []string{requiredIntermediate.Subject.String()},
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	served, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if served.VerifyPeerCertificate == nil {
+		t.Fatal("expected VerifyPeerCertificate to be installed when RequiredIssuerSubjects is set")
+	}
+	if err := served.VerifyPeerCertificate(nil, [][]*x509.Certificate{{leaf, requiredIntermediate}}); err != nil {
+		t.Errorf("expected the required intermediate's chain to be accepted, got: %v", err)
+	}
+	if err := served.VerifyPeerCertificate(nil, [][]*x509.Certificate{{leaf, siblingIntermediate}}); err == nil {
+		t.Error("expected a sibling intermediate's chain to be rejected")
+	}
+}
+
+func TestAddAndRemoveCAProvider(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		clientCA: NewStaticCAContent("base-ca", newTestCACertPEM(t, "base")),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(c.CurrentClientCAPool().Subjects()); got != 1 {
+		t.Fatalf("expected only the base CA to be trusted, got %d subjects", got)
+	}
+
+	extra := NewStaticCAContent("extra-ca", newTestCACertPEM(t, "extra"))
+	if err := c.AddCAProvider(extra); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(c.CurrentClientCAPool().Subjects()); got != 2 {
+		t.Fatalf("expected both CAs to be trusted after AddCAProvider, got %d subjects", got)
+	}
+
+	removed, err := c.RemoveCAProvider("extra-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !removed {
+		t.Fatal("expected RemoveCAProvider to report the provider was found")
+	}
+	if got := len(c.CurrentClientCAPool().Subjects()); got != 1 {
+		t.Fatalf("expected the served config to no longer trust the removed CA, got %d subjects", got)
+	}
+
+	removedAgain, err := c.RemoveCAProvider("extra-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removedAgain {
+		t.Error("expected removing an already-removed provider to report not found")
+	}
+}
+
+func TestRemoveSNICertNotSupported(t *testing.T) {
+	c := &DynamicServingCertificateController{}
+	if err := c.RemoveSNICert("example.com"); err == nil {
+		t.Error("expected RemoveSNICert to error since this controller doesn't track SNI certs")
+	}
+}
+
+func TestCertsWithoutSCT(t *testing.T) {
+	withoutSCT := newTestServingCert(t, "no-sct.example.com")
+	if got := certsWithoutSCT([]tls.Certificate{withoutSCT}); len(got) != 1 {
+		t.Fatalf("expected the cert lacking an SCT extension to be reported, got %v", got)
+	}
+}
+
+func TestSyncCertsCheckSCTPresence(t *testing.T) {
+	newController := func(requireSCT bool) *DynamicServingCertificateController {
+		return &DynamicServingCertificateController{
+			baseTLSConfig:      tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "no-sct.example.com")}},
+			clientCA:           NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+			CheckSCTPresence:   true,
+			RequireSCTPresence: requireSCT,
+		}
+	}
+
+	if err := newController(false).RunOnce(); err != nil {
+		t.Errorf("expected a missing SCT to only warn by default, got error: %v", err)
+	}
+	if err := newController(true).RunOnce(); err == nil {
+		t.Error("expected RunOnce to fail once RequireSCTPresence is set and the cert still lacks an SCT")
+	}
+}
+
+func TestSyncCertsValidatesNewConfigBeforePublishing(t *testing.T) {
+	goodCert := newTestServingCert(t, "api.example.com")
+	unusableCert := newTestServingCert(t, "api.example.com")
+	// Swap in a private key that doesn't correspond to the certificate, so a real TLS handshake against it fails.
+	unusableCert.PrivateKey = newTestServingCert(t, "unrelated").PrivateKey
+
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{Certificates: []tls.Certificate{goodCert}},
+		clientCA:      NewStaticCAContent("test-ca", newTestCACertPEM(t, "test-1")),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error establishing the initial good configuration: %v", err)
+	}
+	goodConfig, err := c.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("unexpected error reading back the initial configuration: %v", err)
+	}
+
+	// Change both the serving cert (to the unusable one) and the client CA content, so syncCerts doesn't just skip
+	// this as a no-op.
+	c.baseTLSConfig = tls.Config{Certificates: []tls.Certificate{unusableCert}}
+	c.clientCA = NewStaticCAContent("test-ca", newTestCACertPEM(t, "test-2"))
+
+	if err := c.RunOnce(); err == nil {
+		t.Fatal("expected RunOnce to fail validating an unusable certificate/key pair")
+	}
+
+	stillServed, err := c.GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("unexpected error reading back the configuration after a failed sync: %v", err)
+	}
+	if !reflect.DeepEqual(stillServed.Certificates, goodConfig.Certificates) {
+		t.Error("expected the previous, working configuration to still be served after validation failed")
+	}
+}
+
+func TestIsClientCATrusted(t *testing.T) {
+	trustedPEM := newTestCACertPEM(t, "shared-subject")
+	// impostorPEM shares the same common name (subject) as trustedPEM but was generated with a different key, so it
+	// must not be considered trusted even though a subject-only comparison would conflate the two.
+	impostorPEM := newTestCACertPEM(t, "shared-subject")
+
+	c := &DynamicServingCertificateController{
+		clientCA: NewStaticCAContent("test-ca", trustedPEM),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	trustedCerts, err := certutil.ParseCertsPEM(trustedPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	impostorCerts, err := certutil.ParseCertsPEM(impostorPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.IsClientCATrusted(trustedCerts[0]) {
+		t.Error("expected the loaded CA cert to be trusted")
+	}
+	if c.IsClientCATrusted(impostorCerts[0]) {
+		t.Error("expected a cert sharing a subject but with a different key to not be trusted")
+	}
+}
+
 func TestNewTLSContent(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -42,7 +414,7 @@ func TestNewTLSContent(t *testing.T) {
 			name:        "missingCA",
 			clientCA:    NewStaticCAContent("test-ca", []byte("")),
 			expected:    nil,
-			expectedErr: `not loading an empty client ca bundle from "test-ca"`,
+			expectedErr: `not loading an empty client ca bundle from "test-ca": not loading an empty client ca bundle`,
 		},
 	}
 
@@ -67,3 +439,823 @@ func TestNewTLSContent(t *testing.T) {
 		})
 	}
 }
+
+func TestNewTLSContentEmptyBundleIsErrEmptyCABundle(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		clientCA: NewStaticCAContent("test-ca", []byte("")),
+	}
+	_, err := c.newTLSContent()
+	if !errors.Is(err, ErrEmptyCABundle) {
+		t.Errorf("expected errors.Is(err, ErrEmptyCABundle) to be true, got err: %v", err)
+	}
+}
+
+// mutableCAContent is a CAContentProvider whose bundle a test can change between syncs, for exercising behavior
+// that depends on the bundle transitioning to or from empty.
+type mutableCAContent struct {
+	name   string
+	bundle []byte
+}
+
+func (c *mutableCAContent) Name() string                   { return c.name }
+func (c *mutableCAContent) CurrentCABundleContent() []byte { return c.bundle }
+
+func TestNewTLSContentTemporarilyEmptyBundleWithinGracePeriod(t *testing.T) {
+	now := time.Now()
+	clientCA := &mutableCAContent{name: "test-ca", bundle: []byte("content-1")}
+	c := &DynamicServingCertificateController{
+		clientCA:                 clientCA,
+		EmptyCABundleGracePeriod: 30 * time.Second,
+		nowFn:                    func() time.Time { return now },
+	}
+
+	if _, err := c.newTLSContent(); err != nil {
+		t.Fatalf("unexpected error loading the initial non-empty bundle: %v", err)
+	}
+	c.currentlyServedContent, _ = c.newTLSContent()
+
+	// The bundle disappears, but we're still within the grace period.
+	clientCA.bundle = nil
+	now = now.Add(10 * time.Second)
+	content, err := c.newTLSContent()
+	if err != nil {
+		t.Fatalf("expected an empty read within the grace period to be tolerated, got error: %v", err)
+	}
+	if !bytes.Equal(content.clientCA.caBundle, []byte("content-1")) {
+		t.Errorf("expected the last-good content to still be served during the grace period, got %q", content.clientCA.caBundle)
+	}
+
+	// The grace period elapses with the bundle still empty.
+	now = now.Add(30 * time.Second)
+	if _, err := c.newTLSContent(); !errors.Is(err, ErrEmptyCABundle) {
+		t.Errorf("expected errors.Is(err, ErrEmptyCABundle) once the grace period elapses, got: %v", err)
+	}
+}
+
+func TestShutdownDrainsQueue(t *testing.T) {
+	c := NewDynamicServingCertificateController(
+		tls.Config{ServerName: "test"},
+		NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		events.NewFakeRecorder(10),
+	)
+
+	go c.runWorker()
+	c.Enqueue()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if drained := c.Shutdown(ctx); !drained {
+		t.Fatal("expected Shutdown to report a clean drain")
+	}
+
+	if c.currentlyServedContent == nil {
+		t.Error("expected the in-flight sync to have completed before Shutdown returned")
+	}
+
+	c.Enqueue()
+	if c.queue.Len() != 0 {
+		t.Error("expected Enqueue to be a no-op once Shutdown has begun")
+	}
+}
+
+// blockingCertPolicy blocks the first call to Validate until proceed is closed, after signaling started. It's used
+// to hold syncCerts in flight long enough for a test to reliably land a concurrent Enqueue() before the sync
+// finishes.
+type blockingCertPolicy struct {
+	once    sync.Once
+	started chan struct{}
+	proceed chan struct{}
+}
+
+func (p *blockingCertPolicy) Validate(*x509.Certificate) error {
+	p.once.Do(func() {
+		close(p.started)
+		<-p.proceed
+	})
+	return nil
+}
+
+func TestProcessNextWorkItemPreservesConcurrentEnqueue(t *testing.T) {
+	policy := &blockingCertPolicy{started: make(chan struct{}), proceed: make(chan struct{})}
+	c := NewDynamicServingCertificateController(
+		tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		events.NewFakeRecorder(10),
+	)
+	c.CertPolicy = policy
+
+	c.Enqueue()
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- c.processNextWorkItem()
+	}()
+
+	<-policy.started // the sync above is now blocked mid-validation, holding c.mu with its work item still "processing"
+
+	// This Enqueue lands while the sync above is in flight, so client-go's workqueue can't add the item back to
+	// the queue immediately -- it's still in the processing set. It marks the item dirty instead and only
+	// requeues it once Done() runs below. A pending flag that's cleared unconditionally on success would lose
+	// track of this.
+	c.Enqueue()
+
+	close(policy.proceed)
+	if ok := <-done; !ok {
+		t.Fatal("expected processNextWorkItem to report it processed an item")
+	}
+
+	c.pendingMu.Lock()
+	pending := c.pending
+	c.pendingMu.Unlock()
+	if !pending {
+		t.Error("expected pending to remain true: a sync was enqueued while the first sync was still in flight")
+	}
+	if got := c.queue.Len(); got != 1 {
+		t.Errorf("expected the concurrent Enqueue to have requeued the work item, got queue.Len() = %d", got)
+	}
+}
+
+func TestEmitEventDedupesRapidIdenticalEvents(t *testing.T) {
+	now := time.Now()
+	recorder := events.NewFakeRecorder(10)
+	c := &DynamicServingCertificateController{
+		eventRecorder:      recorder,
+		EventDedupInterval: 30 * time.Second,
+		nowFn:              func() time.Time { return now },
+	}
+
+	for i := 0; i < 5; i++ {
+		c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "CACertificateReload", "loaded client CA %q", "test-ca")
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected 5 rapid identical events to be deduped to 1, got %d", len(recorder.Events))
+	}
+
+	// A different message isn't deduped against the first.
+	c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "CACertificateReload", "loaded client CA %q", "other-ca")
+	if len(recorder.Events) != 2 {
+		t.Errorf("expected a distinct message to still be recorded, got %d events", len(recorder.Events))
+	}
+
+	// Once EventDedupInterval elapses, the original message is recorded again.
+	now = now.Add(30 * time.Second)
+	c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "CACertificateReload", "loaded client CA %q", "test-ca")
+	if len(recorder.Events) != 3 {
+		t.Errorf("expected the event to be recorded again once EventDedupInterval elapses, got %d events", len(recorder.Events))
+	}
+}
+
+func TestEmitEventRecordsEveryCallWhenDedupDisabled(t *testing.T) {
+	recorder := events.NewFakeRecorder(10)
+	c := &DynamicServingCertificateController{eventRecorder: recorder}
+
+	for i := 0; i < 3; i++ {
+		c.emitEvent(v1.EventTypeWarning, "TLSConfigChanged", "CACertificateReload", "loaded client CA %q", "test-ca")
+	}
+	if len(recorder.Events) != 3 {
+		t.Errorf("expected every call to be recorded with EventDedupInterval unset, got %d", len(recorder.Events))
+	}
+}
+
+func TestHasBeenReadyRequiresEveryProvider(t *testing.T) {
+	ca1 := &mutableCAContent{name: "ca-1"}
+	ca2 := &mutableCAContent{name: "ca-2"}
+	c := &DynamicServingCertificateController{
+		clientCA:              ca1,
+		additionalCAProviders: map[string]CAContentProvider{ca2.Name(): ca2},
+	}
+
+	if c.HasBeenReady() {
+		t.Fatal("expected a controller with no provider content yet to not be ready")
+	}
+
+	ca1.bundle = []byte("content-1")
+	if c.HasBeenReady() {
+		t.Fatal("expected readiness to require every provider, not just one")
+	}
+
+	ca2.bundle = []byte("content-2")
+	if !c.HasBeenReady() {
+		t.Fatal("expected readiness once every provider has contributed content")
+	}
+
+	// A provider going back to empty (e.g. a transient bundle gap) doesn't un-ready it.
+	ca1.bundle = nil
+	if !c.HasBeenReady() {
+		t.Error("expected a provider that has ever been ready to stay ready")
+	}
+}
+
+func TestHasBeenReadyFalseWithNoProvidersRegistered(t *testing.T) {
+	c := &DynamicServingCertificateController{}
+	if c.HasBeenReady() {
+		t.Error("expected a controller with no providers registered at all to not be ready")
+	}
+}
+
+func TestQueueLen(t *testing.T) {
+	c := NewDynamicServingCertificateController(
+		tls.Config{ServerName: "test"},
+		NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		events.NewFakeRecorder(10),
+	)
+
+	if got := c.QueueLen(); got != 0 {
+		t.Errorf("expected an idle controller to report 0, got %d", got)
+	}
+
+	c.Enqueue()
+	if got := c.QueueLen(); got != 1 {
+		t.Errorf("expected the pending item to be reflected, got %d", got)
+	}
+}
+
+// fakeConnAtAddr is a net.Conn stub that only supports LocalAddr, enough to exercise
+// listenerClientCAFor without a real listener.
+type fakeConnAtAddr struct {
+	net.Conn
+	local net.Addr
+}
+
+func (f fakeConnAtAddr) LocalAddr() net.Addr { return f.local }
+
+func TestGetConfigForClientPerListenerClientCA(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{ServerName: "test"},
+		clientCA:      NewStaticCAContent("default-ca", newTestCACertPEM(t, "default")),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	internalCA := NewStaticCAContent("internal-ca", newTestCACertPEM(t, "internal"))
+	c.RegisterListenerClientCA("127.0.0.1:6443", internalCA)
+
+	internalHello := &tls.ClientHelloInfo{Conn: fakeConnAtAddr{local: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 6443}}}
+	externalHello := &tls.ClientHelloInfo{Conn: fakeConnAtAddr{local: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8443}}}
+
+	internalConfig, err := c.GetConfigForClient(internalHello)
+	if err != nil {
+		t.Fatal(err)
+	}
+	externalConfig, err := c.GetConfigForClient(externalHello)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(internalConfig.ClientCAs.Subjects()) != 1 {
+		t.Errorf("expected the internal listener's config to use the registered listener CA, got %d subjects", len(internalConfig.ClientCAs.Subjects()))
+	}
+	if reflect.DeepEqual(internalConfig.ClientCAs.Subjects(), externalConfig.ClientCAs.Subjects()) {
+		t.Error("expected different listeners to be served different ClientCAs pools")
+	}
+}
+
+func TestSyncCertsReportsClientCABundleDiff(t *testing.T) {
+	sharedPEM := newTestCACertPEM(t, "shared")
+	oldOnlyPEM := newTestCACertPEM(t, "old-only")
+	newOnlyPEM := newTestCACertPEM(t, "new-only")
+
+	clientCA := &mutableCAContent{name: "test-ca", bundle: append(append([]byte{}, sharedPEM...), oldOnlyPEM...)}
+	c := &DynamicServingCertificateController{
+		clientCA: clientCA,
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	var added, removed []*x509.Certificate
+	c.ClientCABundleChangeHandler = func(a, r []*x509.Certificate) {
+		added, removed = a, r
+	}
+	fakeRecorder := events.NewFakeRecorder(10)
+	c.eventRecorder = fakeRecorder
+	clientCA.bundle = append(append([]byte{}, sharedPEM...), newOnlyPEM...)
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(added) != 1 || added[0].Subject.CommonName != "new-only" {
+		t.Errorf("expected exactly one added cert with CommonName %q, got %v", "new-only", added)
+	}
+	if len(removed) != 1 || removed[0].Subject.CommonName != "old-only" {
+		t.Errorf("expected exactly one removed cert with CommonName %q, got %v", "old-only", removed)
+	}
+
+	found := false
+	for !found {
+		select {
+		case event := <-fakeRecorder.Events:
+			if strings.Contains(event, "client CA bundle changed") {
+				found = true
+			}
+		default:
+			t.Fatal("expected an event summarizing the client CA bundle diff to be recorded")
+		}
+	}
+}
+
+func TestGetConfigForClientNoCloneReturnsLiveConfig(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{ServerName: "test"},
+		clientCA:      NewStaticCAContent("default-ca", newTestCACertPEM(t, "default")),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	served, err := c.GetConfigForClientNoClone(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := c.currentServingTLSConfig.Load().(*tls.Config)
+	if served != stored {
+		t.Error("expected GetConfigForClientNoClone to return the exact stored config, not a clone")
+	}
+}
+
+func BenchmarkGetConfigForClient(b *testing.B) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{ServerName: "test"},
+		clientCA:      NewStaticCAContent("default-ca", newTestCACertPEM(b, "default")),
+	}
+	if err := c.RunOnce(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.GetConfigForClient(nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetConfigForClientNoClone(b *testing.B) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{ServerName: "test"},
+		clientCA:      NewStaticCAContent("default-ca", newTestCACertPEM(b, "default")),
+	}
+	if err := c.RunOnce(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.GetConfigForClientNoClone(nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReconfigureAll(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:       tls.Config{},
+		clientCA:            NewStaticCAContent("old-ca", newTestCACertPEM(t, "old")),
+		ServingCertProvider: NewStaticServingCertProvider("old-serving-cert", []tls.Certificate{newTestServingCert(t, "old.example.com")}),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	newClientCA := NewStaticCAContent("new-ca", newTestCACertPEM(t, "new"))
+	newServingCertProvider := NewStaticServingCertProvider("new-serving-cert", []tls.Certificate{newTestServingCert(t, "new.example.com")})
+	if err := c.ReconfigureAll(&tls.Config{ServerName: "new"}, newClientCA, newServingCertProvider); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.baseTLSConfig.ServerName != "new" {
+		t.Errorf("expected baseTLSConfig to be replaced, got %q", c.baseTLSConfig.ServerName)
+	}
+	if c.clientCA != newClientCA {
+		t.Errorf("expected clientCA to be replaced")
+	}
+	if c.ServingCertProvider != newServingCertProvider {
+		t.Errorf("expected ServingCertProvider to be replaced")
+	}
+
+	served, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(served.ClientCAs.Subjects()) != 1 {
+		t.Errorf("expected the served config to reflect the new client CA, got %d subjects", len(served.ClientCAs.Subjects()))
+	}
+	if len(served.Certificates) != 1 || len(served.Certificates[0].Certificate) == 0 {
+		t.Errorf("expected the served config to reflect the new serving cert provider")
+	}
+}
+
+func TestReconfigureAllRollsBackOnFailure(t *testing.T) {
+	oldClientCA := NewStaticCAContent("old-ca", newTestCACertPEM(t, "old"))
+	oldServingCertProvider := NewStaticServingCertProvider("old-serving-cert", []tls.Certificate{newTestServingCert(t, "old.example.com")})
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:       tls.Config{ServerName: "old"},
+		clientCA:            oldClientCA,
+		ServingCertProvider: oldServingCertProvider,
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	badClientCA := NewStaticCAContent("bad-ca", []byte(""))
+	newServingCertProvider := NewStaticServingCertProvider("new-serving-cert", []tls.Certificate{newTestServingCert(t, "new.example.com")})
+	err := c.ReconfigureAll(&tls.Config{ServerName: "new"}, badClientCA, newServingCertProvider)
+	if err == nil {
+		t.Fatal("expected an error from reconfiguring with an empty ca bundle")
+	}
+
+	if c.baseTLSConfig.ServerName != "old" {
+		t.Errorf("expected baseTLSConfig to be rolled back, got %q", c.baseTLSConfig.ServerName)
+	}
+	if c.clientCA != oldClientCA {
+		t.Errorf("expected clientCA to be rolled back")
+	}
+	if c.ServingCertProvider != oldServingCertProvider {
+		t.Errorf("expected ServingCertProvider to be rolled back")
+	}
+}
+
+func TestCurrentClientCAPool(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		clientCA: NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := c.CurrentClientCAPool()
+	if len(pool.Subjects()) != 1 {
+		t.Fatalf("expected the returned pool to contain the loaded CA, got %d subjects", len(pool.Subjects()))
+	}
+
+	// mutating the returned pool must not affect the pool backing future serving.
+	pool.AppendCertsFromPEM(newTestCACertPEM(t, "mutated"))
+	if len(pool.Subjects()) != 2 {
+		t.Fatalf("expected mutation to apply to the returned pool")
+	}
+
+	again := c.CurrentClientCAPool()
+	if len(again.Subjects()) != 1 {
+		t.Errorf("expected a fresh call to CurrentClientCAPool to be unaffected by prior mutation, got %d subjects", len(again.Subjects()))
+	}
+}
+
+func TestCurrentContentAge(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &DynamicServingCertificateController{
+		clientCA: NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		nowFn:    func() time.Time { return now },
+	}
+
+	if age := c.CurrentContentAge(); age != 0 {
+		t.Errorf("expected age to be zero before the first sync, got %v", age)
+	}
+
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+	if age := c.CurrentContentAge(); age != 0 {
+		t.Errorf("expected age to be zero immediately after content first loads, got %v", age)
+	}
+
+	now = now.Add(time.Hour)
+	if age := c.CurrentContentAge(); age != time.Hour {
+		t.Errorf("expected age to grow while content is unchanged, got %v", age)
+	}
+
+	// re-running with unchanged content must not reset lastContentChangeTime.
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+	if age := c.CurrentContentAge(); age != time.Hour {
+		t.Errorf("expected age to be unaffected by a no-op sync, got %v", age)
+	}
+
+	now = now.Add(time.Minute)
+	if err := c.ReconfigureAll(&c.baseTLSConfig, NewStaticCAContent("new-ca", newTestCACertPEM(t, "new")), c.ServingCertProvider); err != nil {
+		t.Fatal(err)
+	}
+	if age := c.CurrentContentAge(); age != 0 {
+		t.Errorf("expected age to reset to zero when content actually changes, got %v", age)
+	}
+}
+
+func TestFilterNonCACerts(t *testing.T) {
+	mixedBundle := append(newTestCACertPEM(t, "ca"), newTestLeafCertPEM(t, "leaf")...)
+
+	t.Run("disabled keeps current behavior", func(t *testing.T) {
+		c := &DynamicServingCertificateController{
+			clientCA: NewStaticCAContent("mixed", mixedBundle),
+		}
+		if err := c.RunOnce(); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(c.CurrentClientCAPool().Subjects()); got != 2 {
+			t.Errorf("expected both certs to be trusted by default, got %d subjects", got)
+		}
+	})
+
+	t.Run("enabled skips non-CA certs", func(t *testing.T) {
+		c := &DynamicServingCertificateController{
+			clientCA:         NewStaticCAContent("mixed", mixedBundle),
+			FilterNonCACerts: true,
+		}
+		if err := c.RunOnce(); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(c.CurrentClientCAPool().Subjects()); got != 1 {
+			t.Errorf("expected only the CA cert to be trusted, got %d subjects", got)
+		}
+	})
+}
+
+func TestGetServingCertExpiry(t *testing.T) {
+	t.Run("no synced content", func(t *testing.T) {
+		c := &DynamicServingCertificateController{
+			clientCA: NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		}
+		if _, ok := c.GetServingCertExpiry(); ok {
+			t.Error("expected no serving cert to be reported before the first sync")
+		}
+	})
+
+	t.Run("reflects the live serving cert", func(t *testing.T) {
+		servingCert := newTestServingCert(t, "example.com")
+		c := &DynamicServingCertificateController{
+			baseTLSConfig: tls.Config{Certificates: []tls.Certificate{servingCert}},
+			clientCA:      NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		}
+		if err := c.RunOnce(); err != nil {
+			t.Fatal(err)
+		}
+		expiry, ok := c.GetServingCertExpiry()
+		if !ok {
+			t.Fatal("expected a serving cert to be reported once one is configured")
+		}
+		if !expiry.Equal(servingCert.Leaf.NotAfter) {
+			t.Errorf("expected expiry %v, got %v", servingCert.Leaf.NotAfter, expiry)
+		}
+
+		// The client CA is rotated alongside the serving cert here; syncCerts compares both, so either change alone
+		// would make it pick up the rotated serving cert.
+		rotatedCert := newTestServingCert(t, "example.com")
+		rotatedCA := NewStaticCAContent("test-ca-2", newTestCACertPEM(t, "test-2"))
+		if err := c.ReconfigureAll(&tls.Config{Certificates: []tls.Certificate{rotatedCert}}, rotatedCA, c.ServingCertProvider); err != nil {
+			t.Fatal(err)
+		}
+		expiry, ok = c.GetServingCertExpiry()
+		if !ok {
+			t.Fatal("expected a serving cert to still be reported after rotation")
+		}
+		if !expiry.Equal(rotatedCert.Leaf.NotAfter) {
+			t.Errorf("expected expiry to reflect the rotated cert %v, got %v", rotatedCert.Leaf.NotAfter, expiry)
+		}
+	})
+}
+
+func TestContentHistory(t *testing.T) {
+	firstCA := NewStaticCAContent("test-ca", newTestCACertPEM(t, "first"))
+	c := &DynamicServingCertificateController{
+		clientCA:          firstCA,
+		MaxContentHistory: 2,
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	secondCA := NewStaticCAContent("test-ca-2", newTestCACertPEM(t, "second"))
+	if err := c.ReconfigureAll(&c.baseTLSConfig, secondCA, c.ServingCertProvider); err != nil {
+		t.Fatal(err)
+	}
+
+	thirdCA := NewStaticCAContent("test-ca-3", newTestCACertPEM(t, "third"))
+	if err := c.ReconfigureAll(&c.baseTLSConfig, thirdCA, c.ServingCertProvider); err != nil {
+		t.Fatal(err)
+	}
+
+	history := c.ContentHistory()
+	if len(history) != 2 {
+		t.Fatalf("expected the ring buffer capped at 2 entries, got %d", len(history))
+	}
+	if history[0].ClientCAProviderName != "test-ca-2" || history[1].ClientCAProviderName != "test-ca-3" {
+		t.Errorf("expected the oldest entry to have been dropped, got providers %q and %q", history[0].ClientCAProviderName, history[1].ClientCAProviderName)
+	}
+	if len(history[1].ClientCAFingerprints) != 1 {
+		t.Errorf("expected one client CA fingerprint recorded, got %d", len(history[1].ClientCAFingerprints))
+	}
+	if history[0].Time.After(history[1].Time) {
+		t.Error("expected history entries in chronological order")
+	}
+}
+
+func TestContentHistoryDisabledByDefault(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		clientCA: NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+	if history := c.ContentHistory(); len(history) != 0 {
+		t.Errorf("expected no history recorded with MaxContentHistory unset, got %d entries", len(history))
+	}
+}
+
+func TestOCSPStaple(t *testing.T) {
+	servingCert := newTestServingCert(t, "example.com")
+	staple := []byte("initial-ocsp-response")
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:        tls.Config{Certificates: []tls.Certificate{servingCert}},
+		clientCA:             NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		OCSPResponseProvider: NewStaticOCSPResponseProvider("test-responder", staple),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	tlsConfig, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tlsConfig.Certificates[0].OCSPStaple, staple) {
+		t.Errorf("expected the served certificate to carry the configured OCSP staple, got %q", tlsConfig.Certificates[0].OCSPStaple)
+	}
+	if len(servingCert.OCSPStaple) != 0 {
+		t.Error("expected the original tls.Certificate passed into baseTLSConfig not to be mutated")
+	}
+
+	// The client CA is rotated alongside the staple here; syncCerts compares both, so either change alone would make
+	// it pick up the rotated staple.
+	rotatedStaple := []byte("rotated-ocsp-response")
+	c.OCSPResponseProvider = NewStaticOCSPResponseProvider("test-responder", rotatedStaple)
+	rotatedCA := NewStaticCAContent("test-ca-2", newTestCACertPEM(t, "test-2"))
+	if err := c.ReconfigureAll(&c.baseTLSConfig, rotatedCA, c.ServingCertProvider); err != nil {
+		t.Fatal(err)
+	}
+
+	tlsConfig, err = c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tlsConfig.Certificates[0].OCSPStaple, rotatedStaple) {
+		t.Errorf("expected the served certificate to carry the rotated OCSP staple, got %q", tlsConfig.Certificates[0].OCSPStaple)
+	}
+}
+
+func TestOCSPStapleUnsetByDefault(t *testing.T) {
+	servingCert := newTestServingCert(t, "example.com")
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{Certificates: []tls.Certificate{servingCert}},
+		clientCA:      NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	tlsConfig, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tlsConfig.Certificates[0].OCSPStaple) != 0 {
+		t.Errorf("expected no OCSP staple with no OCSPResponseProvider configured, got %q", tlsConfig.Certificates[0].OCSPStaple)
+	}
+}
+
+// mutableSessionTicketKeyProvider is a SessionTicketKeyProvider whose keys a test can rotate between syncs.
+type mutableSessionTicketKeyProvider struct {
+	name string
+	keys [][32]byte
+}
+
+func (p *mutableSessionTicketKeyProvider) Name() string                         { return p.name }
+func (p *mutableSessionTicketKeyProvider) CurrentSessionTicketKeys() [][32]byte { return p.keys }
+
+func TestSessionTicketKeyRotationProducesNewConfig(t *testing.T) {
+	var key1, key2 [32]byte
+	copy(key1[:], []byte("session-ticket-key-generation-1"))
+	copy(key2[:], []byte("session-ticket-key-generation-2"))
+
+	provider := &mutableSessionTicketKeyProvider{name: "test-keys", keys: [][32]byte{key1}}
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:            tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:                 NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		SessionTicketKeyProvider: provider,
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error on initial sync: %v", err)
+	}
+	firstConfig := c.currentServingTLSConfig.Load()
+
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error resyncing with unchanged keys: %v", err)
+	}
+	if c.currentServingTLSConfig.Load() != firstConfig {
+		t.Error("expected a resync with unchanged session ticket keys to be a no-op")
+	}
+
+	provider.keys = [][32]byte{key2}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error after rotating session ticket keys: %v", err)
+	}
+	if c.currentServingTLSConfig.Load() == firstConfig {
+		t.Error("expected rotating the session ticket keys alone to produce a new served config")
+	}
+}
+
+func TestSessionTicketKeysLeftAtDefaultWhenEmpty(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:            tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:                 NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		SessionTicketKeyProvider: NewStaticSessionTicketKeyProvider("empty-keys", nil),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("expected an empty key set to leave crypto/tls's own default in place, got error: %v", err)
+	}
+}
+
+func TestServingCertFromBaseOnly(t *testing.T) {
+	servingCert := newTestServingCert(t, "example.com")
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{Certificates: []tls.Certificate{servingCert}},
+		clientCA:      NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("expected a serving cert supplied by baseTLSConfig alone to succeed, got: %v", err)
+	}
+
+	tlsConfig, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tlsConfig.Certificates) != 1 {
+		t.Fatalf("expected one serving certificate, got %d", len(tlsConfig.Certificates))
+	}
+}
+
+func TestServingCertFromProviderOnly(t *testing.T) {
+	servingCert := newTestServingCert(t, "example.com")
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:       tls.Config{},
+		clientCA:            NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		ServingCertProvider: NewStaticServingCertProvider("test-serving-cert", []tls.Certificate{servingCert}),
+	}
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("expected a serving cert supplied by ServingCertProvider alone to succeed, got: %v", err)
+	}
+
+	tlsConfig, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tlsConfig.Certificates) != 1 {
+		t.Fatalf("expected one serving certificate, got %d", len(tlsConfig.Certificates))
+	}
+}
+
+func TestInitialBundleServedBeforeFirstSync(t *testing.T) {
+	initialPEM := newTestCACertPEM(t, "initial")
+	realPEM := newTestCACertPEM(t, "real")
+
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{Certificates: []tls.Certificate{newTestServingCert(t, "example.com")}},
+		clientCA:      NewStaticCAContent("test-ca", realPEM),
+		InitialBundle: initialPEM,
+	}
+
+	tlsConfig, err := c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatalf("expected InitialBundle to let GetConfigForClient serve before the first sync, got: %v", err)
+	}
+	initialSubjects := tlsConfig.ClientCAs.Subjects()
+	if len(initialSubjects) != 1 {
+		t.Fatalf("expected exactly one trusted subject from InitialBundle, got %d", len(initialSubjects))
+	}
+
+	if err := c.RunOnce(); err != nil {
+		t.Fatalf("unexpected error syncing the real clientCA content: %v", err)
+	}
+
+	tlsConfig, err = c.GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	syncedSubjects := tlsConfig.ClientCAs.Subjects()
+	if len(syncedSubjects) != 1 {
+		t.Fatalf("expected exactly one trusted subject after syncing, got %d", len(syncedSubjects))
+	}
+	if bytes.Equal(syncedSubjects[0], initialSubjects[0]) {
+		t.Error("expected the real clientCA content to replace InitialBundle's after the first sync")
+	}
+}
+
+func TestServingCertMissingFromBothErrors(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig:       tls.Config{},
+		clientCA:            NewStaticCAContent("test-ca", newTestCACertPEM(t, "test")),
+		ServingCertProvider: NewStaticServingCertProvider("test-serving-cert", nil),
+	}
+	if err := c.RunOnce(); err == nil {
+		t.Fatal("expected an error on first sync when neither baseTLSConfig nor ServingCertProvider supplies a serving certificate")
+	}
+}
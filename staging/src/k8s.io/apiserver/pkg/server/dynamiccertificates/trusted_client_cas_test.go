@@ -0,0 +1,69 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamiccertificates
+
+import (
+	"crypto/tls"
+	"strings"
+	"testing"
+
+	"k8s.io/component-base/metrics/testutil"
+)
+
+func TestSyncCertsSetsTrustedClientCAsGauge(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{ServerName: "test"},
+		clientCA:      NewStaticCAContent("two-ca-bundle", append(newTestCACertPEM(t, "ca-one"), newTestCACertPEM(t, "ca-two")...)),
+	}
+	c.RegisterMetrics()
+	trustedClientCAsGauge.Reset()
+
+	if err := c.syncCerts(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `
+# HELP apiserver_dynamic_serving_trusted_client_cas [ALPHA] Number of certificates currently trusted in the served client CA pool, labeled by provider name.
+# TYPE apiserver_dynamic_serving_trusted_client_cas gauge
+apiserver_dynamic_serving_trusted_client_cas{provider="two-ca-bundle"} 2
+`
+	if err := testutil.CollectAndCompare(trustedClientCAsGauge, strings.NewReader(want), "apiserver_dynamic_serving_trusted_client_cas"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSyncCertsSetsTrustedClientCAsGaugeForOneCA(t *testing.T) {
+	c := &DynamicServingCertificateController{
+		baseTLSConfig: tls.Config{ServerName: "test"},
+		clientCA:      NewStaticCAContent("one-ca-bundle", newTestCACertPEM(t, "ca-one")),
+	}
+	c.RegisterMetrics()
+	trustedClientCAsGauge.Reset()
+
+	if err := c.syncCerts(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `
+# HELP apiserver_dynamic_serving_trusted_client_cas [ALPHA] Number of certificates currently trusted in the served client CA pool, labeled by provider name.
+# TYPE apiserver_dynamic_serving_trusted_client_cas gauge
+apiserver_dynamic_serving_trusted_client_cas{provider="one-ca-bundle"} 1
+`
+	if err := testutil.CollectAndCompare(trustedClientCAsGauge, strings.NewReader(want), "apiserver_dynamic_serving_trusted_client_cas"); err != nil {
+		t.Error(err)
+	}
+}